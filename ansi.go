@@ -0,0 +1,120 @@
+package vox
+
+import (
+	"strings"
+)
+
+const esc = '\u001b'
+
+// csiLen - Returns the length of the control sequence (ESC [ ... final byte) at
+// the start of s, or 0 if s does not start with a complete control sequence.
+func csiLen(s string) int {
+	if len(s) < 2 || s[0] != esc || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 0x20 && c <= 0x3f:
+			// parameter and intermediate bytes
+		case c >= 0x40 && c <= 0x7e:
+			return i + 1
+		default:
+			return 0
+		}
+	}
+	return 0
+}
+
+// rewriteSGR - Calls fn with the parameters of every SGR (color and style)
+// sequence in s and replaces the sequence with one built from the returned
+// parameters. If fn returns no parameters the sequence is removed.
+func rewriteSGR(s string, fn func(params []string) []string) string {
+	if strings.IndexByte(s, esc) == -1 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		n := csiLen(s[i:])
+		if n == 0 || s[i+n-1] != 'm' {
+			if n == 0 {
+				n = 1
+			}
+			b.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		params := []string{"0"}
+		if p := s[i+2 : i+n-1]; p != "" {
+			params = strings.Split(p, ";")
+		}
+		if params = fn(params); len(params) > 0 {
+			b.WriteString("\u001b[" + strings.Join(params, ";") + "m")
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// StripANSI - Removes all terminal escape sequences from a string. This
+// includes colors and other control sequences (CSI), operating system commands
+// (OSC) such as hyperlinks and window titles, and other escape sequences.
+func StripANSI(s string) string {
+	if strings.IndexByte(s, esc) == -1 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] == esc {
+			i += escapeLen(s[i:])
+			continue
+		}
+		j := strings.IndexByte(s[i:], esc)
+		if j == -1 {
+			b.WriteString(s[i:])
+			break
+		}
+		b.WriteString(s[i : i+j])
+		i += j
+	}
+	return b.String()
+}
+
+// escapeLen - Returns the length of the escape sequence at the start of s,
+// which must start with ESC. Unterminated sequences extend to the end of s.
+func escapeLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			switch c := s[i]; {
+			case c >= 0x40 && c <= 0x7e:
+				return i + 1
+			case c < 0x20 || c > 0x3f:
+				return i
+			}
+		}
+		return len(s)
+	case ']', 'P', 'X', '^', '_':
+		// string sequences are terminated by BEL or ST (ESC \)
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == esc && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	}
+	// ESC, any intermediate bytes and a final byte
+	i := 1
+	for i < len(s) && s[i] >= 0x20 && s[i] <= 0x2f {
+		i++
+	}
+	if i < len(s) {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,51 @@
+package vox
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	for in, expected := range map[string]string{
+		"plain":                                           "plain",
+		fmt.Sprint(Red, "red", ResetColor):                "red",
+		Sprintc(Style(Bold, RGB(1, 2, 3)), "x"):           "x",
+		"a\u001b[2K\u001b[1Ab":                            "ab",
+		"\u001b]8;;http://x.io\u0007link\u001b]8;;\u0007": "link",
+		"\u001b]0;title\u001b\\text":                      "text",
+		"\u001b(Bcharset":                                 "charset",
+		"\u001b7saved\u001b8":                             "saved",
+		"unterminated\u001b[31":                           "unterminated",
+		"trailing\u001b":                                  "trailing",
+	} {
+		if res := StripANSI(in); res != expected {
+			t.Errorf("incorrect result for %q: %q", in, res)
+		}
+	}
+}
+
+func TestPlainOutput(t *testing.T) {
+	v := New()
+	rich := &TestPipeline{}
+	plain := &TestPipeline{Plain: true}
+	v.SetPipelines(rich)
+	v.AddPipeline(plain)
+
+	v.Println(Red, "red ", Sprintc(Green, "green"), ResetColor)
+	v.PrintProperty("Name", "Value")
+	v.PrintPlain(Blue, "plain only\n")
+	v.PrintRich(Blue, "rich only\n")
+	v.PrintResult("task", nil)
+
+	expected := "red green\n" +
+		"Name" + strings.Repeat(" ", 51) + "Value\n" +
+		"plain only\n" +
+		"task" + strings.Repeat(" ", 56) + " [OK]\n"
+	if plain.All() != expected {
+		t.Errorf("incorrect plain output: \n%q\n%q", plain.All(), expected)
+	}
+	if strings.Contains(rich.All(), "plain only") {
+		t.Errorf("plain output sent to rich pipeline: %q", rich.All())
+	}
+}
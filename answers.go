@@ -0,0 +1,210 @@
+package vox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoAnswer is returned by prompts in non-interactive mode when no answer is
+// available and the prompt has no usable default value.
+var ErrNoAnswer = errors.New("vox: no answer for prompt in non-interactive mode")
+
+// AnswerSource - Provides answers to prompts without reading the input
+// stream. Answers are looked up by the name or message of the prompt.
+type AnswerSource interface {
+	Answer(name string) (string, bool)
+}
+
+// Answers - An AnswerSource that answers prompts from a map keyed by the name
+// of the prompt. Answers can also be used as a flag.Value, which sets
+// answers from values such as "Name=value":
+//
+//	answers := vox.Answers{}
+//	flag.Var(answers, "answer", "answer a prompt (name=value)")
+type Answers map[string]string
+
+// Answer - Returns the answer for a prompt.
+func (a Answers) Answer(name string) (string, bool) {
+	s, ok := a[name]
+	return s, ok
+}
+
+// String - Returns the answers as a list of name=value pairs.
+func (a Answers) String() string {
+	pairs := make([]string, 0, len(a))
+	for k, v := range a {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set - Sets an answer from a name=value pair.
+func (a Answers) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i == -1 {
+		return fmt.Errorf("answer %q is not in the form name=value", s)
+	}
+	a[s[:i]] = s[i+1:]
+	return nil
+}
+
+// LoadAnswers - Reads answers from a YAML or JSON file containing a single
+// object of prompt names and answers.
+func LoadAnswers(path string) (Answers, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("could not read answers from %s: %s", path, err)
+	}
+	a := Answers{}
+	for k, v := range raw {
+		switch v := v.(type) {
+		case nil:
+			a[k] = ""
+		case []interface{}:
+			// lists answer multiple choice prompts
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			a[k] = strings.Join(items, ",")
+		default:
+			a[k] = fmt.Sprint(v)
+		}
+	}
+	return a, nil
+}
+
+// EnvAnswers - An AnswerSource that answers prompts from environment
+// variables. The variable for a prompt is the prefix followed by the name of
+// the prompt in upper case, with each run of other characters replaced by an
+// underscore. For example the prompt "Install path" is answered by
+// VOX_ANSWER_INSTALL_PATH.
+type EnvAnswers struct {
+	// Prefix - The prefix of the variables. If empty VOX_ANSWER_ is used.
+	Prefix string
+}
+
+// Answer - Returns the answer for a prompt.
+func (e EnvAnswers) Answer(name string) (string, bool) {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = "VOX_ANSWER_"
+	}
+	return os.LookupEnv(prefix + envName(name))
+}
+
+// envName - Converts a prompt name to the form used in variable names.
+func envName(name string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if sep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToUpper(r))
+			sep = false
+		} else {
+			sep = true
+		}
+	}
+	return b.String()
+}
+
+// SetAnswers - Sets the sources used to answer prompts, which are checked in
+// order. Prompts with an answer print it and do not read the input stream.
+func SetAnswers(sources ...AnswerSource) { v.SetAnswers(sources...) }
+
+// SetAnswers - Sets the sources used to answer prompts, which are checked in
+// order. Prompts with an answer print it and do not read the input stream.
+func (v *Vox) SetAnswers(sources ...AnswerSource) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.answers = sources
+}
+
+// SetNonInteractive - When enabled prompts never read the input stream.
+// Prompts without an answer return their default value, or ErrNoAnswer from
+// the Context variants if they have no usable default.
+func SetNonInteractive(enabled bool) { v.SetNonInteractive(enabled) }
+
+// SetNonInteractive - When enabled prompts never read the input stream.
+// Prompts without an answer return their default value, or ErrNoAnswer from
+// the Context variants if they have no usable default.
+func (v *Vox) SetNonInteractive(enabled bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.nonInteractive = enabled
+}
+
+// lookupAnswer - Returns the answer for a prompt from the answer sources.
+func (v *Vox) lookupAnswer(name string) (string, bool) {
+	v.mu.Lock()
+	sources := v.answers
+	v.mu.Unlock()
+	for _, src := range sources {
+		if s, ok := src.Answer(name); ok {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// scripted - Returns true if a prompt will not read the input stream, because
+// it has an answer, a Script is used or vox is non-interactive.
+func (v *Vox) scripted(name string) bool {
+	if _, ok := v.lookupAnswer(name); ok {
+		return true
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.script != nil || v.nonInteractive
+}
+
+// scriptedAnswer - Returns the answer for a prompt that does not read the
+// input stream. The answer comes from a Script or the answer sources. If vox
+// is non-interactive and there is no answer ErrNoAnswer is returned. False is
+// returned if the input stream should be read.
+func (v *Vox) scriptedAnswer(name string) (string, bool, error) {
+	v.mu.Lock()
+	script := v.script
+	nonInteractive := v.nonInteractive
+	v.mu.Unlock()
+	if script != nil {
+		s, err := script.answer(name)
+		return s, true, err
+	}
+	if s, ok := v.lookupAnswer(name); ok {
+		return s, true, nil
+	}
+	if nonInteractive {
+		return "", true, ErrNoAnswer
+	}
+	return "", false, nil
+}
+
+// readAnswer - Returns the scripted answer for a prompt and prints it as if it
+// was typed. Without one a line is read from the input stream. Answers are
+// returned with surrounding whitespace removed.
+func (v *Vox) readAnswer(ctx context.Context, name string) (string, error) {
+	s, ok, err := v.scriptedAnswer(name)
+	if ok {
+		v.Println(s)
+		return strings.TrimSpace(s), err
+	}
+	s, err = v.readLineContext(ctx)
+	return strings.TrimSpace(s), err
+}
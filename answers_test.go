@@ -0,0 +1,114 @@
+package vox
+
+import (
+	"context"
+	"flag"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestAnswers(t *testing.T) {
+	answers := Answers{}
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Var(answers, "answer", "")
+	if err := flags.Parse([]string{"-answer", "Name=a=b", "-answer", "Port=80"}); err != nil {
+		t.Fatal(err)
+	}
+	if answers.String() != "Name=a=b,Port=80" {
+		t.Errorf("incorrect answers: %s", answers)
+	}
+	if err := answers.Set("invalid"); err == nil {
+		t.Error("no error for invalid answer")
+	}
+
+	fs = afero.NewMemMapFs()
+	afero.WriteFile(fs, "/answers.yml", []byte("Name: test\nPort: 8080\nEnvironments: [dev, prod]\n"), 0600)
+	afero.WriteFile(fs, "/answers.json", []byte(`{"Name": "test", "Sure": true}`), 0600)
+	for path, expected := range map[string]string{
+		"/answers.yml":  "Environments=dev,prod,Name=test,Port=8080",
+		"/answers.json": "Name=test,Sure=true",
+	} {
+		answers, err := LoadAnswers(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if answers.String() != expected {
+			t.Errorf("incorrect answers from %s: %s", path, answers)
+		}
+	}
+
+	// booleans answer yes or no prompts
+	afero.WriteFile(fs, "/bools.json", []byte(`{"Continue?": false, "Sure": true}`), 0600)
+	answers, err := LoadAnswers("/bools.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := New()
+	v.SetPipelines(&TestPipeline{Plain: true})
+	v.SetNonInteractive(true)
+	v.SetAnswers(answers)
+	if v.PromptBool("Continue?", true) {
+		t.Error("false answer not used")
+	}
+	if !v.PromptBool("Sure", false) {
+		t.Error("true answer not used")
+	}
+
+	os.Setenv("VOX_ANSWER_INSTALL_PATH", "/opt")
+	defer os.Unsetenv("VOX_ANSWER_INSTALL_PATH")
+	if s, ok := (EnvAnswers{}).Answer("Install path:"); !ok || s != "/opt" {
+		t.Errorf("incorrect answer from environment: %q", s)
+	}
+	if _, ok := (EnvAnswers{Prefix: "APP_"}).Answer("Install path"); ok {
+		t.Error("answer found with a different prefix")
+	}
+}
+
+func TestPromptAnswers(t *testing.T) {
+	v := New()
+	pipeline := &TestPipeline{Plain: true}
+	v.SetPipelines(pipeline)
+	v.SetNonInteractive(true)
+	v.SetAnswers(Answers{
+		"Name":         "test",
+		"Port":         "invalid",
+		"Environment":  "Prod",
+		"Environments": "dev,3",
+	})
+
+	if s := v.Prompt("Name", "default"); s != "test" {
+		t.Errorf("answer not used: %s", s)
+	}
+	if pipeline.All() != "Name [default]: test\n" {
+		t.Errorf("answer not printed: %q", pipeline.All())
+	}
+	if n := v.PromptInt("Port", 80); n != 80 {
+		t.Errorf("default not used for an invalid answer: %d", n)
+	}
+	choices := []string{"dev", "test", "prod"}
+	if s := v.PromptChoice("Environment", choices, 0); s != "prod" {
+		t.Errorf("answer not used: %s", s)
+	}
+	if s := v.PromptMultiChoice("Environments", choices, nil); len(s) != 2 || s[1] != "prod" {
+		t.Errorf("answer not used: %v", s)
+	}
+
+	if ok := v.PromptBool("Sure", true); !ok {
+		t.Error("default not used")
+	}
+	if s, err := v.PromptContext(context.Background(), "Other", "default"); s != "default" || err != nil {
+		t.Errorf("default not used: %q %v", s, err)
+	}
+	if _, err := v.PromptContext(context.Background(), "Other", ""); err != ErrNoAnswer {
+		t.Errorf("incorrect error without a default: %v", err)
+	}
+	if _, err := v.PromptValidateContext(context.Background(), "Count", "", func(s string) error {
+		_, err := strconv.Atoi(s)
+		return err
+	}); err != ErrNoAnswer {
+		t.Errorf("incorrect error without a valid default: %v", err)
+	}
+}
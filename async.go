@@ -0,0 +1,187 @@
+package vox
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy - Determines what an AsyncPipeline does when its queue is
+// full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock - Wait until there is room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest - Discard the entry being written.
+	OverflowDropNewest
+	// OverflowDropOldest - Discard the oldest queued entry to make room.
+	OverflowDropOldest
+)
+
+// DefaultAsyncBufferSize is the queue size used by an AsyncPipeline when no
+// BufferSize is set.
+const DefaultAsyncBufferSize = 1024
+
+// flusher is implemented by pipelines that write output in the background.
+type flusher interface {
+	Flush()
+}
+
+// ErrPipelineClosed is returned when writing to a pipeline that has been
+// closed.
+var ErrPipelineClosed = errors.New("pipeline closed")
+
+// ErrPipelineNotInitialized is returned when writing to an AsyncPipeline that
+// has not been initialized.
+var ErrPipelineNotInitialized = errors.New("pipeline not initialized")
+
+// AsyncPipeline wraps another pipeline and writes to it on a background
+// goroutine. Entries are queued so that slow pipelines, such as the
+// FilePipeline, do not block the caller. Byte oriented pipelines can be wrapped
+// using AdaptPipeline:
+//
+//	vox.AddEventPipeline(&vox.AsyncPipeline{
+//		Target: vox.AdaptPipeline(&vox.FilePipeline{Filepath: "app.log"}),
+//	})
+type AsyncPipeline struct {
+	Target EventPipeline
+	// BufferSize - The number of entries that can be queued. If zero
+	// DefaultAsyncBufferSize is used.
+	BufferSize int
+	// Overflow - What to do when the queue is full.
+	Overflow OverflowPolicy
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan *Entry
+	flushes chan chan struct{}
+	done    chan struct{}
+	dropped uint64
+}
+
+// Config returns the configuration of the wrapped pipeline
+func (a *AsyncPipeline) Config() *PipelineConfig {
+	return a.Target.Config()
+}
+
+// Initialize initializes the wrapped pipeline and starts the background
+// goroutine.
+func (a *AsyncPipeline) Initialize() error {
+	if err := a.Target.Initialize(); err != nil {
+		return err
+	}
+	size := a.BufferSize
+	if size <= 0 {
+		size = DefaultAsyncBufferSize
+	}
+	a.queue = make(chan *Entry, size)
+	a.flushes = make(chan chan struct{})
+	a.done = make(chan struct{})
+	go a.run()
+	return nil
+}
+
+// WriteEntry queues an entry to be written to the wrapped pipeline.
+func (a *AsyncPipeline) WriteEntry(e *Entry) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return ErrPipelineClosed
+	}
+	if a.queue == nil {
+		return ErrPipelineNotInitialized
+	}
+	switch a.Overflow {
+	case OverflowDropNewest:
+		select {
+		case a.queue <- e:
+		default:
+			atomic.AddUint64(&a.dropped, 1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case a.queue <- e:
+				return nil
+			default:
+			}
+			select {
+			case <-a.queue:
+				atomic.AddUint64(&a.dropped, 1)
+			default:
+			}
+		}
+	default:
+		a.queue <- e
+	}
+	return nil
+}
+
+// Dropped returns the number of entries discarded because the queue was full.
+func (a *AsyncPipeline) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Flush blocks until all queued entries have been written.
+func (a *AsyncPipeline) Flush() {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed || a.queue == nil {
+		return
+	}
+	ack := make(chan struct{})
+	a.flushes <- ack
+	<-ack
+}
+
+// Close writes all queued entries and stops the background goroutine. The
+// wrapped pipeline is not closed.
+func (a *AsyncPipeline) Close() {
+	a.mu.Lock()
+	if a.closed || a.queue == nil {
+		a.mu.Unlock()
+		return
+	}
+	a.closed = true
+	close(a.queue)
+	a.mu.Unlock()
+	<-a.done
+}
+
+func (a *AsyncPipeline) run() {
+	defer close(a.done)
+	for {
+		select {
+		case e, ok := <-a.queue:
+			if !ok {
+				return
+			}
+			a.write(e)
+		case ack := <-a.flushes:
+			a.drain()
+			close(ack)
+		}
+	}
+}
+
+// drain - Writes everything currently in the queue.
+func (a *AsyncPipeline) drain() {
+	for {
+		select {
+		case e, ok := <-a.queue:
+			if !ok {
+				return
+			}
+			a.write(e)
+		default:
+			return
+		}
+	}
+}
+
+func (a *AsyncPipeline) write(e *Entry) {
+	if err := a.Target.WriteEntry(e); err != nil {
+		println(err.Error())
+	}
+}
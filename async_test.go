@@ -0,0 +1,69 @@
+package vox
+
+import "testing"
+
+// gatedPipeline blocks every write until the gate is opened. Each write is
+// announced on started before it blocks.
+type gatedPipeline struct {
+	TestPipeline
+	gate    chan struct{}
+	started chan struct{}
+}
+
+func (g *gatedPipeline) WriteEntry(e *Entry) error {
+	g.started <- struct{}{}
+	<-g.gate
+	return g.TestPipeline.WriteEntry(e)
+}
+
+func TestAsyncPipeline(t *testing.T) {
+	target := &TestPipeline{}
+	async := &AsyncPipeline{Target: target}
+	v := New()
+	v.SetEventPipelines(async)
+	for i := 0; i < 100; i++ {
+		v.Println(i)
+	}
+	v.Flush()
+	if len(target.LogLines) != 100 || target.Last() != "99\n" {
+		t.Errorf("not all entries written: %d", len(target.LogLines))
+	}
+	async.Close()
+	if err := async.WriteEntry(&Entry{}); err != ErrPipelineClosed {
+		t.Errorf("expected closed error: %v", err)
+	}
+
+	async = &AsyncPipeline{Target: &TestPipeline{}}
+	if err := async.WriteEntry(&Entry{}); err != ErrPipelineNotInitialized {
+		t.Errorf("expected not initialized error: %v", err)
+	}
+}
+
+func TestAsyncPipelineOverflow(t *testing.T) {
+	for policy, expected := range map[OverflowPolicy]string{
+		OverflowDropNewest: "0\n1\n2\n",
+		OverflowDropOldest: "0\n3\n4\n",
+	} {
+		target := &gatedPipeline{gate: make(chan struct{}), started: make(chan struct{}, 5)}
+		async := &AsyncPipeline{Target: target, BufferSize: 2, Overflow: policy}
+		v := New()
+		v.SetEventPipelines(async)
+
+		v.Println(0)
+		// wait for the first entry to be picked up by the background goroutine
+		<-target.started
+		for i := 1; i < 5; i++ {
+			v.Println(i)
+		}
+		close(target.gate)
+		async.Close()
+
+		if target.All() != expected {
+			t.Errorf("incorrect output for policy %d: %q", policy, target.All())
+		}
+		if async.Dropped() != 2 {
+			t.Errorf("incorrect drop count for policy %d: %d", policy,
+				async.Dropped())
+		}
+	}
+}
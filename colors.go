@@ -2,44 +2,357 @@ package vox
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
-// Color - A structure which represents a single color. This structure should
-// not need to be used directly. Variables for each color are exported in the
-// package.
+type colorMode uint8
+
+const (
+	noColor colorMode = iota
+	basicColor
+	brightColor
+	color256
+	trueColor
+	// defaultColor - The terminal's default foreground or background.
+	defaultColor
+)
+
+// colorValue - A single foreground or background color.
+type colorValue struct {
+	mode    colorMode
+	n       uint8
+	r, g, b uint8
+}
+
+// params - Returns the SGR parameters for the color. The base is 30 for
+// foreground colors and 40 for background colors.
+func (cv colorValue) params(base int) string {
+	switch cv.mode {
+	case basicColor:
+		return strconv.Itoa(base + int(cv.n))
+	case brightColor:
+		return strconv.Itoa(base + 60 + int(cv.n))
+	case color256:
+		return fmt.Sprintf("%d;5;%d", base+8, cv.n)
+	case trueColor:
+		return fmt.Sprintf("%d;2;%d;%d;%d", base+8, cv.r, cv.g, cv.b)
+	case defaultColor:
+		return strconv.Itoa(base + 9)
+	}
+	return ""
+}
+
+// downgrade - Converts the color to the closest color supported by the
+// profile.
+func (cv colorValue) downgrade(p ColorProfile) colorValue {
+	switch {
+	case p == NoColors:
+		return colorValue{}
+	case cv.mode == trueColor && p == ExtendedColors:
+		return colorValue{mode: color256, n: rgbTo256(cv.r, cv.g, cv.b)}
+	case cv.mode == trueColor && p == BasicColors:
+		return rgbTo16(cv.r, cv.g, cv.b)
+	case cv.mode == color256 && p == BasicColors:
+		if cv.n < 16 {
+			return ansi16(cv.n)
+		}
+		r, g, b := color256RGB(cv.n)
+		return rgbTo16(r, g, b)
+	}
+	return cv
+}
+
+// ansi16Palette are the standard xterm values of the 16 basic colors.
+var ansi16Palette = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// colorCubeLevels are the channel values of the 6x6x6 cube in the 256 color
+// palette.
+var colorCubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// ansi16 - Returns the basic or bright color for an index from 0 to 15.
+func ansi16(n uint8) colorValue {
+	if n < 8 {
+		return colorValue{mode: basicColor, n: n}
+	}
+	return colorValue{mode: brightColor, n: n - 8}
+}
+
+// rgbTo16 - Returns the basic or bright color closest to an RGB value.
+func rgbTo16(r, g, b uint8) colorValue {
+	best, bestDist := 0, -1
+	for i, c := range ansi16Palette {
+		dr, dg, db := int(r)-int(c[0]), int(g)-int(c[1]), int(b)-int(c[2])
+		if dist := dr*dr + dg*dg + db*db; bestDist == -1 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return ansi16(uint8(best))
+}
+
+// rgbTo256 - Returns the index of the 256 color palette entry closest to an
+// RGB value.
+func rgbTo256(r, g, b uint8) uint8 {
+	if r == g && g == b {
+		switch {
+		case r < 8:
+			return 16
+		case r > 248:
+			return 231
+		}
+		step := (int(r) - 3) / 10
+		if step > 23 {
+			step = 23
+		}
+		return uint8(232 + step)
+	}
+	cube := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+	return uint8(16 + 36*cube(r) + 6*cube(g) + cube(b))
+}
+
+// color256RGB - Returns the RGB value of a 256 color palette entry.
+func color256RGB(n uint8) (uint8, uint8, uint8) {
+	switch {
+	case n < 16:
+		c := ansi16Palette[n]
+		return c[0], c[1], c[2]
+	case n >= 232:
+		gray := 8 + (n-232)*10
+		return gray, gray, gray
+	}
+	i := n - 16
+	return colorCubeLevels[i/36], colorCubeLevels[(i/6)%6], colorCubeLevels[i%6]
+}
+
+func parseUint8(s string) uint8 {
+	n, _ := strconv.ParseUint(s, 10, 8)
+	return uint8(n)
+}
+
+// styleAttr - A set of text attributes such as bold or underline.
+type styleAttr uint16
+
+const (
+	attrBold styleAttr = 1 << iota
+	attrDim
+	attrItalic
+	attrUnderline
+	attrBlink
+	attrInverse
+	attrStrikethrough
+)
+
+// attrCodes are the SGR parameters for each attribute in the order they are
+// written.
+var attrCodes = []struct {
+	attr styleAttr
+	code string
+}{
+	{attrBold, "1"},
+	{attrDim, "2"},
+	{attrItalic, "3"},
+	{attrUnderline, "4"},
+	{attrBlink, "5"},
+	{attrInverse, "7"},
+	{attrStrikethrough, "9"},
+}
+
+// Color - A structure which represents a terminal color. A color can set the
+// foreground, the background and text styles such as bold. This structure
+// should not need to be used directly. Variables for each color and style are
+// exported in the package and others can be created with Color256, RGB,
+// ParseHex and Style.
 type Color struct {
-	index int
+	fg, bg colorValue
+	attrs  styleAttr
+	reset  bool
 }
 
 func (c Color) String() string {
-	return fmt.Sprintf("\u001b[%dm", 30+c.index)
+	var params []string
+	if c.reset {
+		params = append(params, "0")
+	}
+	for _, ac := range attrCodes {
+		if c.attrs&ac.attr != 0 {
+			params = append(params, ac.code)
+		}
+	}
+	if p := c.fg.params(30); p != "" {
+		params = append(params, p)
+	}
+	if p := c.bg.params(40); p != "" {
+		params = append(params, p)
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "\u001b[" + strings.Join(params, ";") + "m"
+}
+
+// Style - Combines colors and text styles into a single Color. Styles are
+// added together and later colors replace the foreground or background of
+// earlier ones.
+//
+//	vox.Println(vox.Style(vox.Bold, vox.Red), "failed", vox.ResetColor)
+func Style(colors ...Color) Color {
+	var res Color
+	for _, c := range colors {
+		res.reset = res.reset || c.reset
+		res.attrs |= c.attrs
+		if c.fg.mode != noColor {
+			res.fg = c.fg
+		}
+		if c.bg.mode != noColor {
+			res.bg = c.bg
+		}
+	}
+	return res
+}
+
+// Background - Returns a color that applies this color to the background
+// instead of the foreground.
+//
+//	vox.Println(vox.Red.Background(), "failed", vox.ResetColor)
+func (c Color) Background() Color {
+	if c.fg.mode == noColor {
+		return c
+	}
+	c.bg = c.fg
+	c.fg = colorValue{}
+	return c
+}
+
+// On - Returns a color that uses this color for the foreground and the
+// foreground of bg for the background.
+//
+//	vox.Println(vox.White.On(vox.Red), "failed", vox.ResetColor)
+func (c Color) On(bg Color) Color {
+	c.bg = bg.Background().bg
+	return c
+}
+
+// Downgrade - Returns the closest color that is supported by the profile. For
+// NoColors the result contains no colors or styles.
+func (c Color) Downgrade(p ColorProfile) Color {
+	if p == NoColors {
+		return Color{}
+	}
+	c.fg = c.fg.downgrade(p)
+	c.bg = c.bg.downgrade(p)
+	return c
+}
+
+// Color256 - Creates a color from the 256 color terminal palette.
+func Color256(n uint8) Color {
+	return Color{fg: colorValue{mode: color256, n: n}}
+}
+
+// RGB - Creates a 24 bit true color.
+func RGB(r, g, b uint8) Color {
+	return Color{fg: colorValue{mode: trueColor, r: r, g: g, b: b}}
+}
+
+// ParseHex - Creates a true color from a hex string such as "#ff8800" or
+// "f80".
+func ParseHex(hex string) (Color, error) {
+	s := strings.TrimPrefix(hex, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return Color{}, fmt.Errorf("invalid hex color: %s", hex)
+	}
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hex color: %s", hex)
+	}
+	return RGB(uint8(n>>16), uint8(n>>8), uint8(n)), nil
+}
+
+func basic(n uint8) Color {
+	return Color{fg: colorValue{mode: basicColor, n: n}}
+}
+
+func bright(n uint8) Color {
+	return Color{fg: colorValue{mode: brightColor, n: n}}
 }
 
 var (
 	// Back - Black terminal color constant. This can be used as a string inside any of the output functions
-	Black = Color{0}
+	Black = basic(0)
 
 	// Red - Red terminal color constant. This can be used as a string inside any of the output functions
-	Red = Color{1}
+	Red = basic(1)
 
 	// Green - Green terminal color constant. This can be used as a string inside any of the output functions
-	Green = Color{2}
+	Green = basic(2)
 
 	// Yellow - Yellow terminal color constant. This can be used as a string inside any of the output functions
-	Yellow = Color{3}
+	Yellow = basic(3)
 
 	// Blue - Blue terminal color constant. This can be used as a string inside any of the output functions
-	Blue = Color{4}
+	Blue = basic(4)
 
 	// Magenta - Magenta terminal color constant. This can be used as a string inside any of the output functions
-	Magenta = Color{5}
+	Magenta = basic(5)
 
 	// Cyan - Cyan terminal color constant. This can be used as a string inside any of the output functions
-	Cyan = Color{6}
+	Cyan = basic(6)
 
 	// White - White terminal color constant. This can be used as a string inside any of the output functions
-	White = Color{7}
+	White = basic(7)
+
+	// ResetColor - Resets the terminal back to its default colors and removes all
+	// text styles. Versions before styles were added only reset the foreground
+	// color, which DefaultColor still does. This can be used as a string inside
+	// any of the output functions
+	ResetColor = Color{reset: true}
 
-	// ResetColor - Resets the terminal back to its default color. This can be used as a string inside any of the output functions
-	ResetColor = Color{9}
+	// DefaultColor - Resets only the foreground color, leaving the background
+	// and text styles. DefaultColor.Background() resets only the background.
+	DefaultColor = Color{fg: colorValue{mode: defaultColor}}
+)
+
+// Bright variants of the terminal colors. These can be used as a string inside
+// any of the output functions.
+var (
+	BrightBlack   = bright(0)
+	BrightRed     = bright(1)
+	BrightGreen   = bright(2)
+	BrightYellow  = bright(3)
+	BrightBlue    = bright(4)
+	BrightMagenta = bright(5)
+	BrightCyan    = bright(6)
+	BrightWhite   = bright(7)
+)
+
+// Background terminal colors. These can be used as a string inside any of the
+// output functions.
+var (
+	BgBlack   = Black.Background()
+	BgRed     = Red.Background()
+	BgGreen   = Green.Background()
+	BgYellow  = Yellow.Background()
+	BgBlue    = Blue.Background()
+	BgMagenta = Magenta.Background()
+	BgCyan    = Cyan.Background()
+	BgWhite   = White.Background()
+)
+
+// Text styles. These can be used as a string inside any of the output functions
+// or combined with colors using Style.
+var (
+	Bold          = Color{attrs: attrBold}
+	Dim           = Color{attrs: attrDim}
+	Italic        = Color{attrs: attrItalic}
+	Underline     = Color{attrs: attrUnderline}
+	Blink         = Color{attrs: attrBlink}
+	Inverse       = Color{attrs: attrInverse}
+	Strikethrough = Color{attrs: attrStrikethrough}
 )
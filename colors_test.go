@@ -0,0 +1,72 @@
+package vox
+
+import (
+	"testing"
+)
+
+func TestColorString(t *testing.T) {
+	for _, tc := range []struct {
+		color    Color
+		expected string
+	}{
+		{Red, "\u001b[31m"},
+		{BrightWhite, "\u001b[97m"},
+		{BgBlue, "\u001b[44m"},
+		{White.On(Red), "\u001b[37;41m"},
+		{White.On(BgRed), "\u001b[37;41m"},
+		{BrightYellow.On(BrightBlack), "\u001b[93;100m"},
+		{Color256(208), "\u001b[38;5;208m"},
+		{Color256(208).Background(), "\u001b[48;5;208m"},
+		{RGB(1, 2, 3), "\u001b[38;2;1;2;3m"},
+		{Black.On(RGB(9, 8, 7)), "\u001b[30;48;2;9;8;7m"},
+		{ResetColor, "\u001b[0m"},
+		{DefaultColor, "\u001b[39m"},
+		{DefaultColor.Background(), "\u001b[49m"},
+		{Color{}, ""},
+	} {
+		if tc.color.String() != tc.expected {
+			t.Errorf("incorrect color code: %q %q", tc.color.String(), tc.expected)
+		}
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	for hex, expected := range map[string]Color{
+		"#ff8800": RGB(255, 136, 0),
+		"ff8800":  RGB(255, 136, 0),
+		"#f80":    RGB(255, 136, 0),
+		"#000000": RGB(0, 0, 0),
+	} {
+		c, err := ParseHex(hex)
+		if err != nil {
+			t.Errorf("could not parse %s: %s", hex, err.Error())
+		}
+		if c != expected {
+			t.Errorf("incorrect color for %s: %q", hex, c.String())
+		}
+	}
+	for _, hex := range []string{"", "#ff88", "#gg8800"} {
+		if _, err := ParseHex(hex); err == nil {
+			t.Errorf("expected an error for %s", hex)
+		}
+	}
+}
+
+func TestStyle(t *testing.T) {
+	for _, tc := range []struct {
+		color    Color
+		expected string
+	}{
+		{Bold, "\u001b[1m"},
+		{Style(Bold, Red), "\u001b[1;31m"},
+		{Style(Underline, Italic, Green, Blue), "\u001b[3;4;34m"},
+		{Style(Bold, White.On(Red), Strikethrough), "\u001b[1;9;37;41m"},
+		{Style(Dim, BgYellow, Color256(12)), "\u001b[2;38;5;12;43m"},
+		{Style(ResetColor, Inverse, Blink), "\u001b[0;5;7m"},
+		{Style(), ""},
+	} {
+		if tc.color.String() != tc.expected {
+			t.Errorf("incorrect style code: %q %q", tc.color.String(), tc.expected)
+		}
+	}
+}
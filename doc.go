@@ -41,7 +41,7 @@ There are several helper functions for gathering input from the console.
 
 Pipelines
 
-Vox offers pipelines as a way of configuring one or more output streams. Four
+Vox offers pipelines as a way of configuring one or more output streams. Six
 built in pipelines are provided with the package:
 
 - ConsolePipeline - This is the default Pipeline set for any vox instance. This
@@ -50,6 +50,10 @@ pipeline will present colored output to standard out.
 - FilePipeline - This pipeline will redirect all data to a local file. This
 pipeline uses plain output, without color codes.
 
+- RotatingFilePipeline - This pipeline writes to a local file like the
+FilePipeline and rotates the file by size or daily. Old files can be
+compressed and only a set number of them kept.
+
 - TestPipeline - All output will be internally stored in a string slice and
 utility functions are provided to make accessing values easier. This pipeline
 should be used for unit tests.
@@ -57,6 +61,20 @@ should be used for unit tests.
 - WriterPipeline - This is a generic pipeline that allows you to specifiy any
 writer that implements the io.Writer interface.
 
+- JSONPipeline - This pipeline writes each log message to an io.Writer as a
+single line JSON object containing the time, level, message, fields and
+caller.
+
+Pipelines that implement EventPipeline receive each Entry, containing the
+level, plain message, colored text segments and fields, instead of
+preformatted text. They can be added using AddEventPipeline. Any pipeline
+implementing only Pipeline is adapted automatically and receives the formatted
+text.
+
+Any pipeline can be wrapped in an AsyncPipeline, which queues output and writes
+it on a background goroutine. Call Flush before exiting to make sure all
+queued output has been written. Fatal does this automatically.
+
 
 Testing
 
@@ -77,7 +95,8 @@ A Test helper function is provided to make this easier:
 
 You can use the `SendInput` function.  SendInput must be called before
 any prompt function, so that the data is ready in the buffer when `Prompt`
-is called.
+is called. Input is queued, so the answers to several prompts can be sent at
+once. Any io.Reader can also be used as the input stream with `SetInput`.
 
 		func AskForFile() string {
 			return vox.Prompt("Enter a file", "")
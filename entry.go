@@ -0,0 +1,159 @@
+package vox
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Entry - A single piece of output sent to the pipelines. Entries are created
+// for log messages as well as for output from the Print functions, which have
+// a Level of NoLevel.
+type Entry struct {
+	Time  time.Time
+	Level Level
+	// Message - The plain text of the output, without any escape sequences. If
+	// empty it is set from the text of the segments.
+	Message string
+	// Segments - The rich text of the output.
+	Segments []Segment
+	Fields   []Field
+	// Caller - The file and line number that logged the message. This is only
+	// set for log messages.
+	Caller string
+
+	target outputTarget
+}
+
+// Segment - A run of text and the colors applied before it.
+type Segment struct {
+	Colors []Color
+	Text   string
+}
+
+type outputTarget int
+
+const (
+	allOutputs outputTarget = iota
+	richOutputs
+	plainOutputs
+)
+
+// Rich - Returns the rich text of the entry with all color codes.
+func (e *Entry) Rich() string {
+	var b strings.Builder
+	for _, s := range e.Segments {
+		for _, c := range s.Colors {
+			b.WriteString(c.String())
+		}
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+// text - Returns the text of the segments without their colors.
+func (e *Entry) text() string {
+	var b strings.Builder
+	for _, s := range e.Segments {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+// Format - Renders the entry as it is written to byte oriented pipelines. Log
+// messages are followed by their fields and a new line. When plain is true the
+// plain message is used and fields are rendered in logfmt.
+func (e *Entry) Format(plain bool) string {
+	if plain {
+		if e.Level == NoLevel {
+			return e.Message
+		}
+		return e.Message + formatFields(e.Fields) + "\n"
+	}
+	if e.Level == NoLevel {
+		return e.Rich()
+	}
+	return e.Rich() + formatFieldsRich(e.Fields) + "\n"
+}
+
+// segments - Splits printable objects into segments at each color. The rich
+// text of the segments is identical to the result of fmt.Sprint.
+func segments(args ...interface{}) []Segment {
+	var (
+		segs       []Segment
+		cur        Segment
+		prevString bool
+	)
+	for i, arg := range args {
+		isString := arg != nil && reflect.TypeOf(arg).Kind() == reflect.String
+		if i > 0 && !isString && !prevString {
+			cur.Text += " "
+		}
+		prevString = isString
+		if c, ok := arg.(Color); ok {
+			if cur.Text != "" {
+				segs = append(segs, cur)
+				cur = Segment{}
+			}
+			cur.Colors = append(cur.Colors, c)
+			continue
+		}
+		cur.Text += fmt.Sprint(arg)
+	}
+	if cur.Text != "" || len(cur.Colors) > 0 {
+		segs = append(segs, cur)
+	}
+	return segs
+}
+
+// EventPipeline is a pipeline that receives each entry instead of preformatted
+// text, so that it can format the output itself.
+type EventPipeline interface {
+	Config() *PipelineConfig
+	Initialize() error
+	WriteEntry(*Entry) error
+}
+
+// AdaptPipeline - Returns an EventPipeline for a Pipeline. Pipelines that
+// already implement EventPipeline are returned as is, others are wrapped so
+// that they receive the formatted text of each entry.
+func AdaptPipeline(p Pipeline) EventPipeline {
+	if ep, ok := p.(EventPipeline); ok {
+		return ep
+	}
+	return &pipelineAdapter{Pipeline: p}
+}
+
+type pipelineAdapter struct {
+	Pipeline
+	buf []byte
+}
+
+func (a *pipelineAdapter) WriteEntry(e *Entry) error {
+	a.buf = append(a.buf[:0], e.Format(a.Config().Plain)...)
+	_, err := a.Write(a.buf)
+	return err
+}
+
+var pkgPath = reflect.TypeOf(Vox{}).PkgPath()
+
+// caller - Returns the location of the first stack frame outside of this
+// package.
+func caller() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, pkgPath+".") ||
+			strings.HasSuffix(f.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", filepath.Base(f.File), f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package vox
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+type eventRecorder struct {
+	entries []*Entry
+	plain   bool
+}
+
+func (r *eventRecorder) Config() *PipelineConfig {
+	return &PipelineConfig{Plain: r.plain}
+}
+
+func (r *eventRecorder) Initialize() error { return nil }
+
+func (r *eventRecorder) WriteEntry(e *Entry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func TestSegments(t *testing.T) {
+	for _, args := range [][]interface{}{
+		{"plain"},
+		{Red, "red", ResetColor},
+		{Yellow, "[", Green, "OK", Yellow, "]", ResetColor, "\n"},
+		{Red, 5, 6, "x", ResetColor, ResetColor},
+		{1, Blue},
+		{},
+	} {
+		e := &Entry{Segments: segments(args...)}
+		if e.Rich() != fmt.Sprint(args...) {
+			t.Errorf("segments do not match Sprint: %q %q", e.Rich(),
+				fmt.Sprint(args...))
+		}
+	}
+
+	segs := segments(White, "a", Yellow, "", ResetColor, "b")
+	if len(segs) != 2 || segs[0].Text != "a" || len(segs[1].Colors) != 2 ||
+		segs[1].Text != "b" {
+		t.Errorf("incorrect segments: %v", segs)
+	}
+}
+
+func TestEventPipeline(t *testing.T) {
+	v := New()
+	rec := &eventRecorder{}
+	v.SetEventPipelines(rec)
+	v.Println(Red, "text", ResetColor)
+	v.With("k", "v").Error("failed")
+
+	if len(rec.entries) != 2 {
+		t.Fatalf("expected 2 entries: %d", len(rec.entries))
+	}
+	if rec.entries[0].Level != NoLevel ||
+		rec.entries[0].Rich() != fmt.Sprint(Red, "text", ResetColor, "\n") {
+		t.Errorf("incorrect print entry: %q", rec.entries[0].Rich())
+	}
+	e := rec.entries[1]
+	if e.Level != ErrorLevel || e.Message != "failed" || len(e.Fields) != 1 {
+		t.Errorf("incorrect log entry: %v", e)
+	}
+	if e.Format(true) != "failed k=v\n" {
+		t.Errorf("incorrect plain format: %q", e.Format(true))
+	}
+}
+
+func TestAdaptPipeline(t *testing.T) {
+	var rich, plain bytes.Buffer
+	v := New()
+	v.SetPipelines(&WriterPipeline{Writer: &rich})
+	v.AddPipeline(&WriterPipeline{Writer: &plain, Plain: true})
+	v.Printc(Green, "ok")
+	v.PrintRich(Blue, "rich only")
+
+	if rich.String() != Sprintc(Green, "ok")+Blue.String()+"rich only" {
+		t.Errorf("incorrect rich output: %q", rich.String())
+	}
+	if plain.String() != "ok" {
+		t.Errorf("incorrect plain output: %q", plain.String())
+	}
+
+	tp := &TestPipeline{}
+	if AdaptPipeline(tp) != EventPipeline(tp) {
+		t.Error("event pipelines should not be wrapped")
+	}
+}
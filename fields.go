@@ -0,0 +1,176 @@
+package vox
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Field - A key/value pair attached to a log message.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger - A contextual logger derived from a Vox instance. Every message
+// printed through a Logger carries its fields to all pipelines. Loggers are
+// created using With.
+type Logger struct {
+	vox    *Vox
+	fields []Field
+}
+
+// With - Creates a Logger that attaches the given key/value pairs to every
+// message it prints.
+//
+//	vox.With("user", id, "req", reqID).Info("saved")
+func With(keyvals ...interface{}) *Logger { return v.With(keyvals...) }
+
+// With - Creates a Logger that attaches the given key/value pairs to every
+// message it prints.
+func (v *Vox) With(keyvals ...interface{}) *Logger {
+	return &Logger{vox: v, fields: makeFields(keyvals)}
+}
+
+// With - Creates a new Logger containing the fields of this logger and the
+// given key/value pairs.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	fields := make([]Field, 0, len(l.fields)+len(keyvals)/2)
+	fields = append(fields, l.fields...)
+	fields = append(fields, makeFields(keyvals)...)
+	return &Logger{vox: l.vox, fields: fields}
+}
+
+// Fields - Returns the fields attached by this logger.
+func (l *Logger) Fields() []Field {
+	return l.fields
+}
+
+// Tracef - Print trace output with the logger's fields.
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	l.vox.log(TraceLevel, l.fields, fmt.Sprintf(format, args...))
+}
+
+// Trace - Print trace output with the logger's fields.
+func (l *Logger) Trace(args ...interface{}) {
+	l.vox.log(TraceLevel, l.fields, args...)
+}
+
+// Debugf - Print debug output with the logger's fields.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.vox.log(DebugLevel, l.fields, fmt.Sprintf(format, args...))
+}
+
+// Debug - Print debug output with the logger's fields.
+func (l *Logger) Debug(args ...interface{}) {
+	l.vox.log(DebugLevel, l.fields, args...)
+}
+
+// Infof - Print an info output with the logger's fields.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.vox.log(InfoLevel, l.fields, fmt.Sprintf(format, args...))
+}
+
+// Info - Print an info output with the logger's fields.
+func (l *Logger) Info(args ...interface{}) {
+	l.vox.log(InfoLevel, l.fields, args...)
+}
+
+// Alertf - Print an alert output with the logger's fields.
+func (l *Logger) Alertf(format string, args ...interface{}) {
+	l.vox.log(AlertLevel, l.fields, fmt.Sprintf(format, args...))
+}
+
+// Alert - Print an alert output with the logger's fields.
+func (l *Logger) Alert(args ...interface{}) {
+	l.vox.log(AlertLevel, l.fields, args...)
+}
+
+// Errorf - Print error output with the logger's fields.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.vox.log(ErrorLevel, l.fields, fmt.Sprintf(format, args...))
+}
+
+// Error - Print error output with the logger's fields.
+func (l *Logger) Error(args ...interface{}) {
+	l.vox.log(ErrorLevel, l.fields, args...)
+}
+
+// Fatalf - Prints an error message with the logger's fields and then exits the
+// application.
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.Fatal(fmt.Sprintf(format, args...))
+}
+
+// Fatal - Prints an error message with the logger's fields and then exits the
+// application.
+func (l *Logger) Fatal(args ...interface{}) {
+	l.vox.log(FatalLevel, l.fields, args...)
+	l.vox.Flush()
+	os.Exit(-1)
+}
+
+// makeFields - Pairs up a list of alternating keys and values. A trailing key
+// without a value is given a nil value.
+func makeFields(keyvals []interface{}) []Field {
+	fields := make([]Field, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		f := Field{Key: fmt.Sprint(keyvals[i])}
+		if i+1 < len(keyvals) {
+			f.Value = keyvals[i+1]
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// formatFields - Renders fields as logfmt pairs, each preceded by a space.
+func formatFields(fields []Field) string {
+	var out string
+	for _, f := range fields {
+		out += " " + logfmtKey(f.Key) + "=" + logfmtValue(f.Value)
+	}
+	return out
+}
+
+// formatFieldsRich - Renders fields as key=value pairs with colored keys, each
+// preceded by a space.
+func formatFieldsRich(fields []Field) string {
+	var out string
+	for _, f := range fields {
+		out += fmt.Sprint(" ", Cyan, logfmtKey(f.Key), ResetColor, "=",
+			logfmtValue(f.Value))
+	}
+	return out
+}
+
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func logfmtValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	str := fmt.Sprint(value)
+	if str == "" {
+		return `""`
+	}
+	if strings.IndexFunc(str, needsQuote) != -1 {
+		return strconv.Quote(str)
+	}
+	return str
+}
+
+func needsQuote(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == '\\' || !strconv.IsPrint(r)
+}
@@ -0,0 +1,57 @@
+package vox
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	v := New()
+	rich := &TestPipeline{}
+	plain := &TestPipeline{Plain: true}
+	v.SetPipelines(rich)
+	v.AddPipeline(plain)
+
+	logger := v.With("user", 42).With("req", "a b", "err", errors.New("oops"))
+	logger.Info("saved")
+
+	expected := fmt.Sprint(White, "saved", ResetColor,
+		" ", Cyan, "user", ResetColor, "=42",
+		" ", Cyan, "req", ResetColor, `="a b"`,
+		" ", Cyan, "err", ResetColor, "=oops", "\n")
+	if rich.Last() != expected {
+		t.Errorf("incorrect string: \n%s%s", rich.Last(), expected)
+	}
+	if plain.Last() != "saved user=42 req=\"a b\" err=oops\n" {
+		t.Errorf("incorrect string: %s", plain.Last())
+	}
+}
+
+func TestWithDoesNotModifyParent(t *testing.T) {
+	v := New()
+	plain := &TestPipeline{Plain: true}
+	v.SetPipelines(plain)
+
+	parent := v.With("a", 1)
+	parent.With("b", 2)
+	parent.Debug("message")
+	if plain.Last() != "message a=1\n" {
+		t.Errorf("incorrect string: %s", plain.Last())
+	}
+}
+
+func TestLogfmtValue(t *testing.T) {
+	for value, expected := range map[interface{}]string{
+		"simple":    "simple",
+		"":          `""`,
+		"a=b":       `"a=b"`,
+		`say "hi"`:  `"say \"hi\""`,
+		"two\nline": `"two\nline"`,
+		3.5:         "3.5",
+	} {
+		if res := logfmtValue(value); res != expected {
+			t.Errorf("incorrect value for %v: %s", value, res)
+		}
+	}
+}
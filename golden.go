@@ -0,0 +1,182 @@
+package vox
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable that makes MatchGolden write
+// golden files instead of comparing them, when it is set to a value other
+// than an empty string.
+const UpdateGoldenEnv = "VOX_UPDATE_GOLDEN"
+
+// MatchGolden - Fails the test if the output does not match the golden file
+// testdata/<name>.golden. Escape sequences are stored as readable tags such as
+// <red> and <reset>, so that the files can be reviewed. Running the tests with
+// VOX_UPDATE_GOLDEN=1 writes the current output to the file instead.
+func (t *TestPipeline) MatchGolden(tb testing.TB, name string) {
+	tb.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	got := visibleEscapes(t.All())
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			tb.Fatalf("could not create golden file: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			tb.Fatalf("could not write golden file: %s", err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		tb.Errorf("could not read golden file, run with %s=1 to create it: %s", UpdateGoldenEnv, err)
+		return
+	}
+	if string(want) != got {
+		tb.Errorf("output does not match %s:\n%s", path, lineDiff(string(want), got))
+	}
+}
+
+// basicColorNames are the names of the basic colors in SGR order.
+var basicColorNames = [8]string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+}
+
+var attrNames = map[string]string{
+	"0": "reset",
+	"1": "bold",
+	"2": "dim",
+	"3": "italic",
+	"4": "underline",
+	"5": "blink",
+	"7": "inverse",
+	"9": "strikethrough",
+}
+
+// visibleEscapes - Replaces the escape sequences in a string with readable
+// tags. Colors and styles are named, such as <red>, <bg-bright-blue> or
+// <bold>, and other sequences are shown as <esc[2K>.
+func visibleEscapes(s string) string {
+	if strings.IndexByte(s, esc) == -1 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != esc {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		n := escapeLen(s[i:])
+		seq := s[i : i+n]
+		if csiLen(seq) == n && seq[n-1] == 'm' {
+			params := []string{"0"}
+			if p := seq[2 : n-1]; p != "" {
+				params = strings.Split(p, ";")
+			}
+			for _, name := range sgrNames(params) {
+				b.WriteString("<" + name + ">")
+			}
+		} else {
+			b.WriteString("<esc" + strings.Trim(strconv.Quote(seq[1:]), `"`) + ">")
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// sgrNames - Returns a name for each color or style set by SGR parameters.
+func sgrNames(params []string) []string {
+	var names []string
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		if name, ok := attrNames[p]; ok {
+			names = append(names, name)
+			continue
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			names = append(names, p)
+			continue
+		}
+		switch {
+		case n == 39:
+			names = append(names, "default")
+		case n == 49:
+			names = append(names, "bg-default")
+		case n >= 30 && n <= 37:
+			names = append(names, basicColorNames[n-30])
+		case n >= 40 && n <= 47:
+			names = append(names, "bg-"+basicColorNames[n-40])
+		case n >= 90 && n <= 97:
+			names = append(names, "bright-"+basicColorNames[n-90])
+		case n >= 100 && n <= 107:
+			names = append(names, "bg-bright-"+basicColorNames[n-100])
+		case (n == 38 || n == 48) && i+2 < len(params) && params[i+1] == "5":
+			names = append(names, bgPrefix(n)+"color"+params[i+2])
+			i += 2
+		case (n == 38 || n == 48) && i+4 < len(params) && params[i+1] == "2":
+			var rgb [3]uint8
+			for j := range rgb {
+				rgb[j] = parseUint8(params[i+2+j])
+			}
+			names = append(names, fmt.Sprintf("%s#%02x%02x%02x", bgPrefix(n), rgb[0], rgb[1], rgb[2]))
+			i += 4
+		default:
+			names = append(names, p)
+		}
+	}
+	return names
+}
+
+func bgPrefix(n int) string {
+	if n == 48 {
+		return "bg-"
+	}
+	return ""
+}
+
+// lineDiff - Returns the lines of two strings, marking lines only in want
+// with - and lines only in got with +.
+func lineDiff(want, got string) string {
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+	// lengths of the longest common subsequences of the remaining lines
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return out.String()
+}
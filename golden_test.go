@@ -0,0 +1,53 @@
+package vox
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMatchGolden(t *testing.T) {
+	v := New()
+	pipeline := v.Test()
+	v.PrintProperty("Name", "vox")
+	v.PrintResult("Build", nil)
+	v.Println(Style(Bold, RGB(255, 136, 0).On(Color256(17))), "styled", ResetColor)
+	pipeline.MatchGolden(t, "match_golden")
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		return
+	}
+
+	rt := &recordingT{}
+	v.Println("extra")
+	pipeline.MatchGolden(rt, "match_golden")
+	pipeline.MatchGolden(rt, "missing")
+	if len(rt.errors) != 2 ||
+		!strings.HasSuffix(rt.errors[0], "+ extra\n  \n") ||
+		!strings.HasPrefix(rt.errors[1], "could not read golden file") {
+		t.Errorf("incorrect failures: %q", rt.errors)
+	}
+}
+
+func TestVisibleEscapes(t *testing.T) {
+	for in, expected := range map[string]string{
+		"plain":                                                    "plain",
+		Sprintc(Red, "red"):                                        "<red>red<reset>",
+		Sprintc(BrightBlue.On(Green), "x"):                         "<bright-blue><bg-green>x<reset>",
+		Sprintc(Style(Bold, Underline), "x"):                       "<bold><underline>x<reset>",
+		Sprintc(Color256(208), "x"):                                "<color208>x<reset>",
+		Sprintc(White.On(RGB(1, 2, 255)), "x"):                     "<white><bg-#0102ff>x<reset>",
+		"a\u001b[2Kb\u001b[m":                                      "a<esc[2K>b<reset>",
+		DefaultColor.String() + DefaultColor.Background().String(): "<default><bg-default>",
+	} {
+		if res := visibleEscapes(in); res != expected {
+			t.Errorf("incorrect result for %q: %s", in, res)
+		}
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	expected := "  a\n- b\n+ B\n  c\n+ d\n"
+	if res := lineDiff("a\nb\nc", "a\nB\nc\nd"); res != expected {
+		t.Errorf("incorrect diff:\n%s", res)
+	}
+}
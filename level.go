@@ -0,0 +1,107 @@
+package vox
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Level - The severity of a log message. Messages below the minimum level of a
+// Vox instance, or below the MinLevel of a pipeline, are discarded.
+type Level int
+
+const (
+	// NoLevel - The zero level. As a minimum level it disables filtering.
+	NoLevel Level = iota
+	// TraceLevel - Very detailed diagnostic output.
+	TraceLevel
+	// DebugLevel - Diagnostic output useful during development.
+	DebugLevel
+	// InfoLevel - General informational messages.
+	InfoLevel
+	// AlertLevel - Warnings that should be brought to the user's attention.
+	AlertLevel
+	// ErrorLevel - Errors that do not stop the application.
+	ErrorLevel
+	// FatalLevel - Errors that cause the application to exit.
+	FatalLevel
+)
+
+var levelNames = map[Level]string{
+	TraceLevel: "trace",
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	AlertLevel: "alert",
+	ErrorLevel: "error",
+	FatalLevel: "fatal",
+}
+
+// levelColors - The console color used for each level. Levels without an entry
+// are printed without color.
+var levelColors = map[Level]Color{
+	InfoLevel:  White,
+	AlertLevel: Yellow,
+	ErrorLevel: Red,
+	FatalLevel: Red,
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel - Converts a level name, such as "debug" or "error", into a Level.
+// The name is case insensitive and "warn" or "warning" are accepted as aliases
+// for the alert level.
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "warn", "warning":
+		return AlertLevel, nil
+	}
+	for l, n := range levelNames {
+		if n == name {
+			return l, nil
+		}
+	}
+	return NoLevel, fmt.Errorf("unknown log level: %s", name)
+}
+
+// SetLevel - Sets the minimum level for log messages. Messages below this level
+// are not sent to any pipeline.
+func SetLevel(l Level) { v.SetLevel(l) }
+
+// SetLevel - Sets the minimum level for log messages. Messages below this level
+// are not sent to any pipeline.
+func (v *Vox) SetLevel(l Level) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.level = l
+}
+
+// enabled - Returns true if messages of the given level should be printed.
+func (v *Vox) enabled(l Level) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return l >= v.level
+}
+
+// log - Sends a message to every pipeline that accepts the given level. Rich
+// pipelines receive the message colored for the level followed by colored
+// fields, plain pipelines receive the fields in logfmt.
+func (v *Vox) log(l Level, fields []Field, args ...interface{}) {
+	if !v.enabled(l) {
+		return
+	}
+	msg := fmt.Sprint(args...)
+	segs := []Segment{{Text: msg}}
+	if c, ok := levelColors[l]; ok {
+		segs = segments(c, msg, ResetColor)
+	}
+	v.emit(&Entry{
+		Time:     time.Now(),
+		Level:    l,
+		Segments: segs,
+		Fields:   fields,
+		Caller:   caller(),
+	})
+}
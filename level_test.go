@@ -0,0 +1,63 @@
+package vox
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSetLevel(t *testing.T) {
+	v := New()
+	pipeline := v.Test()
+	v.SetLevel(InfoLevel)
+	v.Debug("hidden")
+	v.Trace("hidden")
+	if pipeline.All() != "" {
+		t.Errorf("messages below level should be dropped: %s", pipeline.All())
+	}
+	v.Alert("shown")
+	expected := fmt.Sprint(Yellow, "shown", ResetColor, "\n")
+	if pipeline.Last() != expected {
+		t.Errorf("incorrect string: \n%s%s", pipeline.Last(), expected)
+	}
+}
+
+func TestPipelineMinLevel(t *testing.T) {
+	v := New()
+	console := &TestPipeline{}
+	file := &TestPipeline{Plain: true, MinLevel: AlertLevel}
+	v.SetPipelines(console)
+	v.AddPipeline(file)
+
+	v.Debug("debug message")
+	v.Error("error message")
+	v.Println("printed")
+
+	expected := fmt.Sprint("debug message\n", Red, "error message", ResetColor,
+		"\n", "printed\n")
+	if console.All() != expected {
+		t.Errorf("incorrect string: \n%s%s", console.All(), expected)
+	}
+	if file.All() != "error message\nprinted\n" {
+		t.Errorf("incorrect string: %s", file.All())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, expected := range map[string]Level{
+		"trace":   TraceLevel,
+		"DEBUG":   DebugLevel,
+		"warning": AlertLevel,
+		"error":   ErrorLevel,
+	} {
+		l, err := ParseLevel(name)
+		if err != nil {
+			t.Errorf("could not parse %s: %s", name, err.Error())
+		}
+		if l != expected {
+			t.Errorf("incorrect level for %s: %s", name, l)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
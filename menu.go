@@ -0,0 +1,315 @@
+package vox
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+// menuHeight - The most options shown at once by an interactive menu.
+const menuHeight = 10
+
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyBackspace
+	keyCancel
+	keyIgnored
+)
+
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// readKey - Reads a single key press from a terminal in raw mode.
+func readKey(r *bufio.Reader) (key, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return key{}, err
+	}
+	switch c {
+	case '\r', '\n':
+		return key{code: keyEnter}, nil
+	case 3, 4:
+		return key{code: keyCancel}, nil
+	case 8, 127:
+		return key{code: keyBackspace}, nil
+	case 14:
+		return key{code: keyDown}, nil
+	case 16:
+		return key{code: keyUp}, nil
+	case esc:
+		// a lone escape cancels, arrow keys are sent as ESC [ A or ESC O A
+		if r.Buffered() == 0 {
+			return key{code: keyCancel}, nil
+		}
+		if b, _ := r.Peek(1); b[0] != '[' && b[0] != 'O' {
+			return key{code: keyCancel}, nil
+		}
+		r.ReadByte()
+		var final byte
+		for r.Buffered() > 0 {
+			if final, _ = r.ReadByte(); final >= 0x40 && final <= 0x7e {
+				break
+			}
+		}
+		switch final {
+		case 'A':
+			return key{code: keyUp}, nil
+		case 'B':
+			return key{code: keyDown}, nil
+		}
+		return key{code: keyIgnored}, nil
+	}
+	if unicode.IsControl(c) {
+		return key{code: keyIgnored}, nil
+	}
+	return key{code: keyRune, r: c}, nil
+}
+
+// menu - The state of an interactive selection menu.
+type menu struct {
+	choices  []string
+	multi    bool
+	selected []bool
+	filter   string
+	// matches - The indexes of the choices that match the filter.
+	matches []int
+	cursor  int
+	offset  int
+	// current - The index of the choice under the cursor, which is kept while
+	// the filter changes.
+	current int
+}
+
+func newMenu(choices []string, defaults []int, multi bool) *menu {
+	m := &menu{
+		choices:  choices,
+		multi:    multi,
+		selected: make([]bool, len(choices)),
+	}
+	for _, idx := range defaults {
+		if idx < 0 || idx >= len(choices) {
+			continue
+		}
+		if multi {
+			m.selected[idx] = true
+		} else {
+			m.current = idx
+		}
+	}
+	m.update()
+	return m
+}
+
+// update - Finds the choices that match the filter and keeps the cursor on the
+// current choice if it still matches.
+func (m *menu) update() {
+	m.matches = m.matches[:0]
+	m.cursor = 0
+	for idx, c := range m.choices {
+		if fuzzyMatch(m.filter, c) {
+			if idx == m.current {
+				m.cursor = len(m.matches)
+			}
+			m.matches = append(m.matches, idx)
+		}
+	}
+	m.move(0)
+}
+
+// move - Moves the cursor within the matching choices and scrolls the visible
+// options so that the cursor is shown.
+func (m *menu) move(n int) {
+	if m.cursor += n; m.cursor >= len(m.matches) {
+		m.cursor = len(m.matches) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if len(m.matches) > 0 {
+		m.current = m.matches[m.cursor]
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+menuHeight {
+		m.offset = m.cursor - menuHeight + 1
+	}
+	if m.offset > len(m.matches)-menuHeight {
+		m.offset = len(m.matches) - menuHeight
+	}
+	if m.offset < 0 {
+		m.offset = 0
+	}
+}
+
+// handle - Updates the menu for a key press. It returns true when the menu is
+// finished.
+func (m *menu) handle(k key) bool {
+	switch k.code {
+	case keyUp:
+		m.move(-1)
+	case keyDown:
+		m.move(1)
+	case keyEnter:
+		return m.multi || len(m.matches) > 0
+	case keyBackspace:
+		if m.filter != "" {
+			r := []rune(m.filter)
+			m.filter = string(r[:len(r)-1])
+			m.update()
+		}
+	case keyRune:
+		switch {
+		case k.r == ' ' && m.multi:
+			if len(m.matches) > 0 {
+				idx := m.matches[m.cursor]
+				m.selected[idx] = !m.selected[idx]
+			}
+		case k.r == 'j' && m.filter == "":
+			return m.handle(key{code: keyDown})
+		case k.r == 'k' && m.filter == "":
+			return m.handle(key{code: keyUp})
+		default:
+			m.filter += string(k.r)
+			m.update()
+		}
+	}
+	return false
+}
+
+// result - Returns the indexes of the chosen options.
+func (m *menu) result() []int {
+	if !m.multi {
+		if len(m.matches) == 0 {
+			return nil
+		}
+		return []int{m.matches[m.cursor]}
+	}
+	res := []int{}
+	for idx, ok := range m.selected {
+		if ok {
+			res = append(res, idx)
+		}
+	}
+	return res
+}
+
+// lines - Renders the options shown by the menu.
+func (m *menu) lines() []string {
+	var lines []string
+	if len(m.matches) == 0 {
+		lines = append(lines, Sprintc(Dim, "  no matches"))
+	}
+	end := m.offset + menuHeight
+	if end > len(m.matches) {
+		end = len(m.matches)
+	}
+	for i := m.offset; i < end; i++ {
+		idx := m.matches[i]
+		line := m.choices[idx]
+		if m.multi {
+			box := "[ ] "
+			if m.selected[idx] {
+				box = "[x] "
+			}
+			line = box + line
+		}
+		if i == m.cursor {
+			lines = append(lines, Sprintc(Cyan, "> ", line))
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
+
+// fuzzyMatch - Returns true if the characters of the pattern appear in s in
+// order, ignoring case.
+func fuzzyMatch(pattern, s string) bool {
+	s = strings.ToLower(s)
+	for _, r := range strings.ToLower(pattern) {
+		i := strings.IndexRune(s, r)
+		if i == -1 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
+
+// selectMenu - Shows an interactive menu on the terminal and returns the
+// indexes of the chosen options. If the menu is canceled with ctrl-c, ctrl-d
+// or escape ErrInterrupted is returned. False is returned if the input or
+// standard out is not a terminal, the terminal could not be put in raw mode,
+// or a line is still being read for an earlier prompt.
+func (v *Vox) selectMenu(msg string, choices []string, defaults []int, multi bool) ([]int, bool, error) {
+	v.mu.Lock()
+	pending := v.pending != nil
+	reader := v.reader
+	v.mu.Unlock()
+	fd, ok := terminalFd(v.input())
+	// the menu is drawn directly on standard out, where it would not be seen
+	// if the output is redirected
+	if !ok || pending || !isTerminal(os.Stdout) {
+		return nil, false, nil
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	m := newMenu(choices, defaults, multi)
+	drawn := 0
+	var res []int
+	for {
+		// move to the start of the menu and draw it over the previous one
+		var b strings.Builder
+		if drawn > 0 {
+			fmt.Fprintf(&b, "\r\u001b[%dA", drawn)
+		}
+		b.WriteString("\r\u001b[J")
+		fmt.Fprint(&b, Yellow, msg, ": ", ResetColor, m.filter, "\r\n")
+		lines := m.lines()
+		b.WriteString(strings.Join(lines, "\r\n"))
+		drawn = len(lines)
+		v.writeTerminal(b.String())
+
+		var k key
+		if k, err = readKey(reader); err == nil && k.code == keyCancel {
+			err = ErrInterrupted
+		}
+		if err != nil {
+			break
+		}
+		if m.handle(k) {
+			res = m.result()
+			break
+		}
+	}
+	v.writeTerminal(fmt.Sprintf("\r\u001b[%dA\r\u001b[J", drawn))
+	term.Restore(fd, state)
+
+	var (
+		valid []int
+		names []string
+	)
+	for _, idx := range res {
+		if idx >= 0 && idx < len(choices) {
+			valid = append(valid, idx)
+			names = append(names, choices[idx])
+		}
+	}
+	v.Printf("%s%s : %s%s\n", Yellow, msg, ResetColor, strings.Join(names, ", "))
+	return valid, true, err
+}
@@ -0,0 +1,78 @@
+package vox
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// runMenu - Sends the keys in input to a menu until it is finished.
+func runMenu(m *menu, input string) bool {
+	r := bufio.NewReader(strings.NewReader(input))
+	for {
+		k, err := readKey(r)
+		if err != nil || k.code == keyCancel {
+			return false
+		}
+		if m.handle(k) {
+			return true
+		}
+	}
+}
+
+func TestMenu(t *testing.T) {
+	choices := []string{"development", "testing", "staging", "production"}
+	for input, expected := range map[string][]int{
+		"\r":                        {1},
+		"\u001b[B\u001b[B\r":        {3},
+		"jjjjjk\r":                  {2},
+		"\u001bOA\r":                {0},
+		"prd\r":                     {3},
+		"st\u007f\u007fdev\r":       {0},
+		"stag\r":                    {2},
+		"xyz\r\u007f\u007f\u007f\r": {1},
+	} {
+		m := newMenu(choices, []int{1}, false)
+		if !runMenu(m, input) {
+			t.Errorf("menu not finished for %q", input)
+			continue
+		}
+		if res := m.result(); fmt.Sprint(res) != fmt.Sprint(expected) {
+			t.Errorf("incorrect result for %q: %v", input, res)
+		}
+	}
+
+	m := newMenu(choices, []int{0}, true)
+	if !runMenu(m, " jj \rignored") {
+		t.Fatal("menu not finished")
+	}
+	if res := m.result(); fmt.Sprint(res) != "[2]" {
+		t.Errorf("incorrect result: %v", res)
+	}
+	if runMenu(newMenu(choices, nil, false), "j\u001b") {
+		t.Error("escape did not cancel the menu")
+	}
+}
+
+func TestMenuLines(t *testing.T) {
+	choices := make([]string, 15)
+	for i := range choices {
+		choices[i] = fmt.Sprintf("option %d", i+1)
+	}
+	m := newMenu(choices, []int{12}, false)
+	lines := m.lines()
+	if len(lines) != menuHeight {
+		t.Fatalf("incorrect number of lines: %d", len(lines))
+	}
+	if lines[0] != "  option 4" ||
+		lines[menuHeight-1] != Sprintc(Cyan, "> ", "option 13") {
+		t.Errorf("incorrect lines: %q", lines)
+	}
+
+	m = newMenu(choices[:2], []int{1}, true)
+	expected := []string{Sprintc(Cyan, "> ", "[ ] option 1"), "  [x] option 2"}
+	if lines := m.lines(); fmt.Sprint(lines) != fmt.Sprint(expected) {
+		t.Errorf("incorrect lines: %q", lines)
+	}
+}
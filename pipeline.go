@@ -1,9 +1,13 @@
 package vox
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/afero"
 )
@@ -18,6 +22,9 @@ type PipelineConfig struct {
 	// stripped from the output. This is normally used for outputs directed towards
 	// files.
 	Plain bool
+	// MinLevel - Log messages below this level are not sent to the pipeline.
+	// Output from the Print functions is not affected.
+	MinLevel Level
 }
 
 // Pipeline represents a specific log pipeline
@@ -27,29 +34,53 @@ type Pipeline interface {
 	Initialize() error
 }
 
-// ConsolePipeline a log pipeline that outputs directly to STDERR
-type ConsolePipeline struct{}
+// ConsolePipeline a log pipeline that outputs directly to STDOUT. Colors are
+// converted to those supported by the terminal. If the terminal does not
+// support colors the pipeline becomes a plain pipeline.
+type ConsolePipeline struct {
+	MinLevel Level
+	// Colors - The colors supported by the terminal. If not set they are
+	// detected using DetectColorProfile when the pipeline is initialized.
+	Colors  ColorProfile
+	profile ColorProfile
+}
 
 // Config returns the pipeline configuration
 func (c *ConsolePipeline) Config() *PipelineConfig {
 	return &PipelineConfig{
-		Plain: false,
+		Plain:    c.profile == NoColors,
+		MinLevel: c.MinLevel,
 	}
 }
 
 // Write sends data to the output Stdout
 func (c *ConsolePipeline) Write(b []byte) (int, error) {
+	switch c.profile {
+	case NoColors, BasicColors, ExtendedColors:
+		out := rewriteSGR(string(b), func(params []string) []string {
+			return downgradeParams(params, c.profile)
+		})
+		if _, err := io.WriteString(os.Stdout, out); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
 	return os.Stdout.Write(b)
 }
 
-// Initialize has no logic for a ConsolePipeline
+// Initialize determines the colors supported by the terminal
 func (c *ConsolePipeline) Initialize() error {
+	c.profile = c.Colors
+	if c.profile == AutoColors {
+		c.profile = DetectColorProfile(os.Stdout)
+	}
 	return nil
 }
 
 // FilePipeline sends output into a local file
 type FilePipeline struct {
 	Filepath string
+	MinLevel Level
 	file     afero.File
 }
 
@@ -61,7 +92,8 @@ func (f *FilePipeline) Close() {
 // Config returns the pipline configuration
 func (f *FilePipeline) Config() *PipelineConfig {
 	return &PipelineConfig{
-		Plain: true,
+		Plain:    true,
+		MinLevel: f.MinLevel,
 	}
 }
 
@@ -81,37 +113,60 @@ func (f *FilePipeline) Initialize() error {
 	return err
 }
 
-// TestPipeline a pipeline that can be used in tests
+// TestPipeline a pipeline that can be used in tests. The formatted output is
+// stored in LogLines and each entry received is stored in Entries.
 type TestPipeline struct {
 	LogLines []string
+	Entries  []*Entry
 	Plain    bool
+	MinLevel Level
+	mu       sync.Mutex
 }
 
 // Config returns the pipline configuration
 func (t *TestPipeline) Config() *PipelineConfig {
 	return &PipelineConfig{
-		Plain: t.Plain,
+		Plain:    t.Plain,
+		MinLevel: t.MinLevel,
 	}
 }
 
 func (t *TestPipeline) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.LogLines = append(t.LogLines, string(b))
 	return len(b), nil
 }
 
+// WriteEntry stores the entry and its formatted output
+func (t *TestPipeline) WriteEntry(e *Entry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Entries = append(t.Entries, e)
+	t.LogLines = append(t.LogLines, e.Format(t.Plain))
+	return nil
+}
+
 // Initialize sets up the testing pipeline
 func (t *TestPipeline) Initialize() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.LogLines = []string{}
+	t.Entries = []*Entry{}
 	return nil
 }
 
 // All returns all output data
 func (t *TestPipeline) All() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	return strings.Join(t.LogLines, "")
 }
 
 // Last returns the last section of data sent
 func (t *TestPipeline) Last() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	if len(t.LogLines) == 0 {
 		return ""
 	}
@@ -120,19 +175,23 @@ func (t *TestPipeline) Last() string {
 
 // Clear removes all items in the pipelines buffer
 func (t *TestPipeline) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.LogLines = []string{}
+	t.Entries = []*Entry{}
 }
 
 // WriterPipeline implements a generic pipeline powered by an io.Writer stream
 type WriterPipeline struct {
-	Writer io.Writer
-	Plain  bool
+	Writer   io.Writer
+	Plain    bool
+	MinLevel Level
 }
 
-// Config returns a configuration for the pipeline. Plain is specified on the
-// pipeline itself and patched into the configuration.
+// Config returns a configuration for the pipeline. Plain and MinLevel are
+// specified on the pipeline itself and patched into the configuration.
 func (w *WriterPipeline) Config() *PipelineConfig {
-	return &PipelineConfig{Plain: w.Plain}
+	return &PipelineConfig{Plain: w.Plain, MinLevel: w.MinLevel}
 }
 
 // Write sends data into the specified writer.
@@ -144,3 +203,77 @@ func (w *WriterPipeline) Write(b []byte) (int, error) {
 func (w *WriterPipeline) Initialize() error {
 	return nil
 }
+
+// JSONPipeline writes one JSON object per line for each log event into an
+// io.Writer. Output from the Print functions is written as an object containing
+// only the time and message. This pipeline is an EventPipeline.
+type JSONPipeline struct {
+	Writer   io.Writer
+	MinLevel Level
+}
+
+// Config returns the pipeline configuration. JSON output is always plain.
+func (j *JSONPipeline) Config() *PipelineConfig {
+	return &PipelineConfig{Plain: true, MinLevel: j.MinLevel}
+}
+
+// Write encodes raw output as a JSON object.
+func (j *JSONPipeline) Write(b []byte) (int, error) {
+	err := j.WriteEntry(&Entry{Time: time.Now(), Message: string(b)})
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// WriteEntry encodes an entry as a JSON object.
+func (j *JSONPipeline) WriteEntry(e *Entry) error {
+	je := jsonEntry{
+		Time:    e.Time,
+		Level:   e.Level.String(),
+		Message: strings.TrimSuffix(e.Message, "\n"),
+		Caller:  e.Caller,
+	}
+	if len(e.Fields) > 0 {
+		je.Fields = make(map[string]interface{}, len(e.Fields))
+		for _, f := range e.Fields {
+			je.Fields[f.Key] = jsonValue(f.Value)
+		}
+	}
+	return j.encode(je)
+}
+
+// Initialize has no logic in a JSONPipeline
+func (j *JSONPipeline) Initialize() error {
+	return nil
+}
+
+func (j *JSONPipeline) encode(je jsonEntry) error {
+	b, err := json.Marshal(je)
+	if err != nil {
+		return err
+	}
+	_, err = j.Writer.Write(append(b, '\n'))
+	return err
+}
+
+type jsonEntry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level,omitempty"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Caller  string                 `json:"caller,omitempty"`
+}
+
+// jsonValue - Converts a field value into something that can be encoded. Errors
+// are encoded as their message and values that cannot be marshaled are
+// formatted as strings.
+func jsonValue(value interface{}) interface{} {
+	if err, ok := value.(error); ok {
+		return err.Error()
+	}
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return value
+}
@@ -1,7 +1,12 @@
 package vox
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 )
@@ -26,3 +31,42 @@ func TestFilePipeline(t *testing.T) {
 		t.Errorf("data missmatch: %s", string(b))
 	}
 }
+
+func TestJSONPipeline(t *testing.T) {
+	var buf bytes.Buffer
+	v := New()
+	v.SetPipelines(&JSONPipeline{Writer: &buf})
+	v.With("user", 42, "err", errors.New("oops")).Alert("saved")
+	v.Println("plain text")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines: %s", buf.String())
+	}
+	var entry struct {
+		Time    time.Time
+		Level   string
+		Message string
+		Fields  map[string]interface{}
+		Caller  string
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err.Error())
+	}
+	if entry.Level != "alert" || entry.Message != "saved" {
+		t.Errorf("incorrect entry: %s", lines[0])
+	}
+	if entry.Fields["user"] != float64(42) || entry.Fields["err"] != "oops" {
+		t.Errorf("incorrect fields: %s", lines[0])
+	}
+	if !strings.HasPrefix(entry.Caller, "pipeline_test.go:") {
+		t.Errorf("incorrect caller: %s", entry.Caller)
+	}
+	if time.Since(entry.Time) > time.Minute {
+		t.Errorf("incorrect time: %s", entry.Time)
+	}
+	if !strings.Contains(lines[1], `"message":"plain text"`) ||
+		strings.Contains(lines[1], "level") {
+		t.Errorf("incorrect print entry: %s", lines[1])
+	}
+}
@@ -1,36 +1,655 @@
 package vox
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gosuri/uilive"
+	"golang.org/x/term"
 )
 
-// ProgressBar - A structure that controls displaying a progress bar in the
-// console.
-type progress struct {
-	Writer    *uilive.Writer
-	Max       int
-	Current   int
-	StartTime time.Time
+// progressRefresh - The shortest time between redraws of the progress bars.
+// Changes made in between are drawn when it has passed.
+var progressRefresh = 50 * time.Millisecond
+
+// progressNow - Returns the time used for the rates, estimates and redraws of
+// progress bars. It can be replaced during tests.
+var progressNow = time.Now
+
+// rateInterval - The shortest time between samples of a bar's rate.
+const rateInterval = 500 * time.Millisecond
+
+// progressTick - The time between redraws of active progress bars, so that the
+// rate, estimate and elapsed time are updated while no progress is made.
+var progressTick = rateInterval
+
+// rateSmoothing - The weight of the newest sample in the moving average of a
+// bar's rate.
+const rateSmoothing = 0.3
+
+// progressManager - Draws the active progress bars, stacked in the order they
+// were added, below all other output. If the output is not a terminal the bars
+// are not redrawn, and the final line of each bar is printed when it is done.
+type progressManager struct {
+	mu      sync.Mutex
+	out     io.Writer
+	profile ColorProfile
+	style   *progressStyle
+	live    bool
+	writer  *uilive.Writer
+	bars    []*ProgressBar
+	shown   bool
+	drawn   time.Time
+	timer   *time.Timer
+	tick    *time.Timer
+}
+
+func newProgressManager(out io.Writer) *progressManager {
+	m := &progressManager{out: out, profile: TrueColors}
+	m.style, _ = compileProgressStyle(ProgressStyle{})
+	if f, ok := out.(*os.File); ok {
+		m.profile = DetectColorProfile(f)
+		m.live = isTerminal(f)
+	}
+	return m
+}
+
+// width - Returns the width of the terminal the bars are drawn on, or zero if
+// it is unknown.
+func (m *progressManager) width() int {
+	f, ok := m.out.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	w, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return w
+}
+
+// add - Adds a bar below the active bars.
+func (m *progressManager) add(b *ProgressBar) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.live {
+		m.bars = append(m.bars, b)
+		return
+	}
+	if len(m.bars) == 0 {
+		m.writer = uilive.New()
+		m.writer.Out = m.out
+	}
+	m.bars = append(m.bars, b)
+	m.draw()
+	if m.tick == nil {
+		m.startTick()
+	}
+}
+
+// startTick - Redraws the bars after progressTick, and again after each
+// redraw until all bars are done. The lock must be held.
+func (m *progressManager) startTick() {
+	var t *time.Timer
+	t = time.AfterFunc(progressTick, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		// the bars were done, or other bars were added, after the timer fired
+		if m.tick != t {
+			return
+		}
+		m.tick = nil
+		m.draw()
+		if len(m.bars) > 0 {
+			m.startTick()
+		}
+	})
+	m.tick = t
+}
+
+// update - Redraws the bars after a change. Redraws are limited by
+// progressRefresh unless force is true. The lock must be held.
+func (m *progressManager) update(force bool) {
+	if !m.live {
+		m.printDone()
+		return
+	}
+	if len(m.bars) == 0 {
+		return
+	}
+	wait := progressRefresh - progressNow().Sub(m.drawn)
+	if force || wait <= 0 {
+		m.draw()
+		return
+	}
+	if m.timer == nil {
+		m.timer = time.AfterFunc(wait, func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.timer = nil
+			m.draw()
+		})
+	}
+}
+
+// draw - Draws the bars over their previous lines. When all bars are done
+// they are left on the screen and the next bars are drawn below them. The lock
+// must be held.
+func (m *progressManager) draw() {
+	if len(m.bars) == 0 {
+		return
+	}
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	var b strings.Builder
+	done := true
+	width := m.width()
+	for _, bar := range m.bars {
+		b.WriteString(bar.render(width))
+		b.WriteString("\n")
+		done = done && bar.done
+	}
+	m.writer.Write([]byte(m.downgrade(b.String())))
+	m.writer.Flush()
+	m.shown = true
+	m.drawn = progressNow()
+	if done {
+		m.bars = nil
+		m.shown = false
+		if m.tick != nil {
+			m.tick.Stop()
+			m.tick = nil
+		}
+	}
+}
+
+// printDone - Prints the final line of each bar that is done and stops
+// tracking it. It is used instead of drawing when the output is not a
+// terminal. The lock must be held.
+func (m *progressManager) printDone() {
+	active := m.bars[:0]
+	for _, bar := range m.bars {
+		if !bar.done {
+			active = append(active, bar)
+			continue
+		}
+		io.WriteString(m.out, m.downgrade(bar.render(0)+"\n"))
+	}
+	m.bars = active
+}
+
+// downgrade - Converts the colors in rendered bars to the color profile of
+// the output.
+func (m *progressManager) downgrade(out string) string {
+	switch m.profile {
+	case NoColors, BasicColors, ExtendedColors:
+		out = rewriteSGR(out, func(params []string) []string {
+			return downgradeParams(params, m.profile)
+		})
+	}
+	return out
+}
+
+// hide - Removes the bars from the screen so that other output can be
+// printed in their place, and keeps them from being drawn until the returned
+// function is called. The bars are drawn again once progressRefresh has passed
+// since they were last drawn, so that frequent output does not redraw them
+// each time.
+func (m *progressManager) hide() (show func()) {
+	m.mu.Lock()
+	if m.shown {
+		// an empty write is not flushed, the reset code clears the lines instead
+		m.writer.Write([]byte(ResetColor.String()))
+		m.writer.Flush()
+		m.shown = false
+	}
+	return func() {
+		if m.live {
+			m.update(false)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// ProgressBar - A progress bar shown in the console. Progress bars are drawn
+// below all other output, and several bars can be shown at the same time. A
+// ProgressBar is safe for use from multiple goroutines, and once it is done
+// further changes are ignored.
+type ProgressBar struct {
+	m       *progressManager
+	style   *progressStyle
+	label   string
+	current int
+	total   int
+	start   time.Time
+	done    bool
+	// rate - The moving average of items per second, which is zero until the
+	// first sample is taken.
+	rate         float64
+	sampledAt    time.Time
+	sampledCount int
+}
+
+// NewProgress - Starts a progress bar that is done when its value reaches max.
+func NewProgress(max int) *ProgressBar { return v.NewProgress(max) }
+
+// NewProgress - Starts a progress bar that is done when its value reaches max.
+func (v *Vox) NewProgress(max int) *ProgressBar { return v.AddProgress("", max) }
+
+// AddProgress - Adds a progress bar with a label, which is shown below any
+// other active progress bars.
+func AddProgress(label string, total int) *ProgressBar { return v.AddProgress(label, total) }
+
+// AddProgress - Adds a progress bar with a label, which is shown below any
+// other active progress bars.
+func (v *Vox) AddProgress(label string, total int) *ProgressBar {
+	v.mu.Lock()
+	m := v.bars
+	v.mu.Unlock()
+	b := &ProgressBar{
+		m:     m,
+		label: label,
+		total: total,
+		start: progressNow(),
+	}
+	b.sampledAt = b.start
+	m.add(b)
+	return b
+}
+
+// Inc - Increments the current value. If it reaches the total the bar is
+// done.
+func (b *ProgressBar) Inc() { b.Add(1) }
+
+// Add - Adds n to the current value. If it reaches the total the bar is done.
+func (b *ProgressBar) Add(n int) {
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+	b.set(b.current + n)
+}
+
+// Set - Sets the current value. If it reaches the total the bar is done.
+func (b *ProgressBar) Set(current int) {
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+	b.set(current)
+}
+
+// set - Sets the current value. The lock must be held.
+func (b *ProgressBar) set(current int) {
+	if b.done {
+		return
+	}
+	b.current = current
+	b.sample()
+	if b.total > 0 && b.current >= b.total {
+		b.done = true
+	}
+	b.m.update(b.done)
+}
+
+// SetTotal - Sets the value at which the bar is done.
+func (b *ProgressBar) SetTotal(total int) {
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+	if b.done {
+		return
+	}
+	b.total = total
+	b.set(b.current)
+}
+
+// SetLabel - Sets the label shown before the bar.
+func (b *ProgressBar) SetLabel(label string) {
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+	if b.done {
+		return
+	}
+	b.label = label
+	b.m.update(false)
+}
+
+// SetStyle - Sets how the bar is drawn, instead of the style set with
+// SetProgressStyle. An error is returned if the template is not valid.
+func (b *ProgressBar) SetStyle(style ProgressStyle) error {
+	s, err := compileProgressStyle(style)
+	if err != nil {
+		return err
+	}
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+	b.style = s
+	b.m.update(false)
+	return nil
 }
 
-// StartProgress - Start outputing a progressbar.
+// Done - Marks the bar as done. Once all bars are done they are left on the
+// screen and further output is printed below them.
+func (b *ProgressBar) Done() {
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+	if b.done {
+		return
+	}
+	b.done = true
+	b.m.update(true)
+}
+
+// sample - Updates the moving average of the rate if the sample interval has
+// passed. It is also called when the bar is drawn, so that the rate of a bar
+// that has stalled falls towards zero. The lock must be held.
+func (b *ProgressBar) sample() {
+	if b.done {
+		return
+	}
+	now := progressNow()
+	dt := now.Sub(b.sampledAt)
+	if dt < rateInterval {
+		return
+	}
+	rate := float64(b.current-b.sampledCount) / dt.Seconds()
+	if b.sampledAt.Equal(b.start) {
+		b.rate = rate
+	} else {
+		b.rate = rateSmoothing*rate + (1-rateSmoothing)*b.rate
+	}
+	b.sampledAt = now
+	b.sampledCount = b.current
+}
+
+// percent - Returns the percentage of the total that is complete. The lock must
+// be held.
+func (b *ProgressBar) percent() float64 {
+	if b.total <= 0 {
+		return 0
+	}
+	return clampPercent(float64(b.current) / float64(b.total) * 100)
+}
+
+// clampPercent - Limits a percentage to the range 0 to 100, as the current
+// value can be set below zero or above the total.
+func clampPercent(p float64) float64 {
+	return math.Max(0, math.Min(100, p))
+}
+
+// eta - Returns the estimated time until the bar is done, or false if it
+// cannot be estimated yet. The lock must be held.
+func (b *ProgressBar) eta() (time.Duration, bool) {
+	if b.done {
+		return 0, true
+	}
+	if b.rate <= 0 || b.total <= 0 {
+		return 0, false
+	}
+	left := float64(b.total-b.current) / b.rate
+	return time.Duration(left * float64(time.Second)), true
+}
+
+// render - Returns the line shown for the bar. If the style has no width the
+// bar fills the rest of the terminal width, if it is known. The lock must be
+// held.
+func (b *ProgressBar) render(termWidth int) string {
+	style := b.style
+	if style == nil {
+		style = b.m.style
+	}
+	b.sample()
+	percent := b.percent()
+	data := progressData{
+		Label:   b.label,
+		Bar:     barMarker,
+		Current: b.current,
+		Total:   b.total,
+		Percent: fmt.Sprintf("%3.0f%%", percent),
+		Rate:    formatRate(b.rate),
+		ETA:     "--",
+		Elapsed: formatDuration(progressNow().Sub(b.start)),
+	}
+	if d, ok := b.eta(); ok {
+		data.ETA = formatDuration(d)
+	}
+	var line strings.Builder
+	if err := style.tmpl.Execute(&line, data); err != nil {
+		return err.Error()
+	}
+	if !strings.Contains(line.String(), barMarker) {
+		return line.String()
+	}
+	width := style.Width
+	if width == 0 {
+		width = 10
+		if termWidth > 0 {
+			rest := StripANSI(strings.Replace(line.String(), barMarker, "", -1))
+			// the last column is left empty so that the line does not wrap
+			width = termWidth - utf8.RuneCountInString(rest) - 1
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	// a bar that was not found by validation, such as one behind a condition,
+	// is still replaced
+	return strings.Replace(line.String(), barMarker, style.bar(percent, width), -1)
+}
+
+// formatRate - Formats a rate of items per second.
+func formatRate(rate float64) string {
+	if rate < 10 {
+		return fmt.Sprintf("%.1f/s", rate)
+	}
+	return fmt.Sprintf("%.0f/s", rate)
+}
+
+// formatDuration - Formats a duration in whole seconds, such as 45s, 1m05s or
+// 2h03m.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := d / time.Hour
+	m := d % time.Hour / time.Minute
+	s := d % time.Minute / time.Second
+	switch {
+	case h > 0:
+		return fmt.Sprintf("%dh%02dm", h, m)
+	case m > 0:
+		return fmt.Sprintf("%dm%02ds", m, s)
+	}
+	return fmt.Sprintf("%ds", s)
+}
+
+// DefaultProgressTemplate - The template used to draw progress bars if the
+// style does not set one.
+const DefaultProgressTemplate = "{{if .Label}}{{.Label}} {{end}}[{{.Current}}/{{.Total}}] " +
+	"{{.Bar}} {{.Percent}} {{.Rate}} ETA {{.ETA}} {{.Elapsed}}"
+
+// ProgressStyle - Controls how progress bars are drawn. Fields that are not
+// set use the defaults.
+type ProgressStyle struct {
+	// Template - A text/template for the line of each bar. The fields Label,
+	// Bar, Current, Total, Percent, Rate, ETA and Elapsed are available. If
+	// empty DefaultProgressTemplate is used.
+	Template string
+	// Width - The number of characters in the bar. If zero the bar fills the
+	// rest of the terminal width, or is 10 characters wide if the output is
+	// not a terminal.
+	Width int
+	// Fill - The character for the complete part of the bar. The default is =.
+	Fill rune
+	// Empty - The character for the incomplete part of the bar. The default
+	// is -, or a space when Blocks is set.
+	Empty rune
+	// Head - A character drawn at the end of the complete part of the bar.
+	Head rune
+	// Blocks - Draws the complete part of the bar with Unicode block
+	// characters, which can fill part of a character. Fill and Head are not
+	// used.
+	Blocks bool
+	// FillColor, EmptyColor, HeadColor - The colors of each part of the bar.
+	FillColor  Color
+	EmptyColor Color
+	HeadColor  Color
+}
+
+// barMarker - Stands for the bar in a rendered template, until the width of
+// the rest of the line is known.
+const barMarker = "\x00bar\x00"
+
+// blocks are the characters for 1/8 to 8/8 of a filled character.
+var blocks = []rune("▏▎▍▌▋▊▉█")
+
+// progressData - The fields available to progress bar templates.
+type progressData struct {
+	Label   string
+	Bar     string
+	Current int
+	Total   int
+	Percent string
+	Rate    string
+	ETA     string
+	Elapsed string
+}
+
+// progressStyle - A ProgressStyle with its defaults applied and its template
+// parsed.
+type progressStyle struct {
+	ProgressStyle
+	tmpl *template.Template
+}
+
+func compileProgressStyle(s ProgressStyle) (*progressStyle, error) {
+	if s.Template == "" {
+		s.Template = DefaultProgressTemplate
+	}
+	if s.Fill == 0 {
+		s.Fill = '='
+	}
+	if s.Empty == 0 {
+		s.Empty = '-'
+		if s.Blocks {
+			s.Empty = ' '
+		}
+	}
+	tmpl, err := template.New("progress").Parse(s.Template)
+	if err != nil {
+		return nil, err
+	}
+	// unknown fields are only found when the template is executed
+	var line strings.Builder
+	data := progressData{
+		Label:   "label",
+		Bar:     barMarker,
+		Current: 1,
+		Total:   2,
+		Percent: " 50%",
+		Rate:    "1.0/s",
+		ETA:     "1s",
+		Elapsed: "1s",
+	}
+	if err := tmpl.Execute(&line, data); err != nil {
+		return nil, err
+	}
+	if strings.Count(line.String(), barMarker) > 1 {
+		return nil, errors.New("vox: a progress template can only contain one bar")
+	}
+	return &progressStyle{ProgressStyle: s, tmpl: tmpl}, nil
+}
+
+// bar - Draws a bar of the given width filled to a percentage.
+func (s *progressStyle) bar(percent float64, width int) string {
+	var (
+		fill, head string
+		b          strings.Builder
+		colored    bool
+	)
+	percent = clampPercent(percent)
+	if s.Blocks {
+		eighths := int(math.Round(percent / 100 * float64(width) * 8))
+		fill = strings.Repeat(string(blocks[7]), eighths/8)
+		if eighths%8 > 0 {
+			fill += string(blocks[eighths%8-1])
+		}
+	} else {
+		n := int(percent / 100 * float64(width))
+		fill = strings.Repeat(string(s.Fill), n)
+		if s.Head != 0 && n < width {
+			head = string(s.Head)
+		}
+	}
+	empty := strings.Repeat(string(s.Empty), width-utf8.RuneCountInString(fill+head))
+	for _, part := range []struct {
+		text  string
+		color Color
+	}{{fill, s.FillColor}, {head, s.HeadColor}, {empty, s.EmptyColor}} {
+		if part.text == "" {
+			continue
+		}
+		if c := part.color.String(); c != "" {
+			b.WriteString(c)
+			colored = true
+		} else if colored {
+			b.WriteString(ResetColor.String())
+			colored = false
+		}
+		b.WriteString(part.text)
+	}
+	if colored {
+		b.WriteString(ResetColor.String())
+	}
+	return b.String()
+}
+
+// SetProgressStyle - Sets how progress bars are drawn. An error is returned if
+// the template is not valid.
+func SetProgressStyle(style ProgressStyle) error { return v.SetProgressStyle(style) }
+
+// SetProgressStyle - Sets how progress bars are drawn. An error is returned if
+// the template is not valid.
+func (v *Vox) SetProgressStyle(style ProgressStyle) error {
+	s, err := compileProgressStyle(style)
+	if err != nil {
+		return err
+	}
+	v.mu.Lock()
+	m := v.bars
+	v.mu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.style = s
+	m.update(false)
+	return nil
+}
+
+// StartProgress - Start outputing a progressbar. The bar is controlled with
+// IncProgress, SetProgress and StopProgress, which do nothing if no bar was
+// started. Use NewProgress to control several bars.
 func StartProgress(current, max int) { v.StartProgress(current, max) }
 
-// StartProgress - Start outputing a progressbar.
+// StartProgress - Start outputing a progressbar. The bar is controlled with
+// IncProgress, SetProgress and StopProgress, which do nothing if no bar was
+// started. Use NewProgress to control several bars.
 func (v *Vox) StartProgress(current, max int) {
-	v.progress = &progress{
-		Writer:    uilive.New(),
-		Max:       max,
-		Current:   current,
-		StartTime: time.Now(),
-	}
-	v.progress.Writer.Out = os.Stdout
-	v.progress.Writer.Start()
+	b := v.NewProgress(max)
+	b.Set(current)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.progress = b
+}
+
+// startedProgress - Returns the bar started by StartProgress.
+func (v *Vox) startedProgress() (*ProgressBar, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.progress, v.progress != nil
 }
 
 // IncProgress - Increment the current progress value by name. If the new
@@ -42,11 +661,9 @@ func IncProgress() { v.IncProgress() }
 // Current value is equal to the Max value StopProgress will be called
 // automatically.
 func (v *Vox) IncProgress() {
-	v.progress.Current++
-	if v.progress.Current == v.progress.Max {
-		v.StopProgress()
+	if b, ok := v.startedProgress(); ok {
+		b.Inc()
 	}
-	v.writeProgress()
 }
 
 // SetProgress - Sets the current progress value.
@@ -54,18 +671,9 @@ func SetProgress(current int) { v.SetProgress(current) }
 
 // SetProgress - Sets the current progress value.
 func (v *Vox) SetProgress(current int) {
-	v.progress.Current = current
-	v.writeProgress()
-}
-
-func (v *Vox) writeProgress() {
-	elapsed := time.Since(v.progress.StartTime)
-	perc := (float64(v.progress.Current) / float64(v.progress.Max)) * float64(10)
-	barStr := strings.Repeat("-", 10)
-	barStr = strings.Replace(barStr, "-", "=", int(perc))
-	line := fmt.Sprintf("[%d/%d] %s %s", v.progress.Current, v.progress.Max,
-		barStr, elapsed)
-	fmt.Fprintln(v.progress.Writer, line)
+	if b, ok := v.startedProgress(); ok {
+		b.Set(current)
+	}
 }
 
 // StopProgress - Stops outputing a progress bar and closes associated writers.
@@ -77,5 +685,7 @@ func StopProgress() { v.StopProgress() }
 // This is called automatically if the Current value equals, or exceeds, the
 // maximum value.
 func (v *Vox) StopProgress() {
-	v.progress.Writer.Stop()
+	if b, ok := v.startedProgress(); ok {
+		b.Done()
+	}
 }
@@ -0,0 +1,337 @@
+package vox
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setupProgress - Draws progress bars into a buffer, along with all other
+// output, at a fixed time, without limiting redraws and without periodic
+// redraws.
+func setupProgress() (*Vox, *bytes.Buffer) {
+	current := time.Date(2017, 6, 1, 10, 30, 0, 0, time.Local)
+	progressNow = func() time.Time { return current }
+	progressRefresh = 0
+	progressTick = time.Hour
+	buf := &bytes.Buffer{}
+	v := New()
+	v.bars = newProgressManager(buf)
+	v.bars.live = true
+	v.SetPipelines(&WriterPipeline{Writer: buf, Plain: true})
+	return v, buf
+}
+
+func TestProgressBars(t *testing.T) {
+	v, buf := setupProgress()
+	defer func() {
+		progressNow = time.Now
+		progressRefresh = 50 * time.Millisecond
+		progressTick = rateInterval
+	}()
+
+	a := v.AddProgress("a", 2)
+	b := v.AddProgress("b", 4)
+	a.Inc()
+	v.Println("log")
+	b.SetLabel("c")
+	b.Set(4)
+	a.Done()
+	a.Inc()
+	v.Println("after")
+
+	expected := "a [0/2] ----------   0% 0.0/s ETA -- 0s\n" +
+		"a [0/2] ----------   0% 0.0/s ETA -- 0s\nb [0/4] ----------   0% 0.0/s ETA -- 0s\n" +
+		"a [1/2] =====-----  50% 0.0/s ETA -- 0s\nb [0/4] ----------   0% 0.0/s ETA -- 0s\n" +
+		"log\n" +
+		"a [1/2] =====-----  50% 0.0/s ETA -- 0s\nb [0/4] ----------   0% 0.0/s ETA -- 0s\n" +
+		"a [1/2] =====-----  50% 0.0/s ETA -- 0s\nc [0/4] ----------   0% 0.0/s ETA -- 0s\n" +
+		"a [1/2] =====-----  50% 0.0/s ETA -- 0s\nc [4/4] ========== 100% 0.0/s ETA 0s 0s\n" +
+		"a [1/2] =====-----  50% 0.0/s ETA 0s 0s\nc [4/4] ========== 100% 0.0/s ETA 0s 0s\n" +
+		"after\n"
+	if out := StripANSI(buf.String()); out != expected {
+		t.Errorf("incorrect output:\n%s", out)
+	}
+}
+
+func TestProgressNotTerminal(t *testing.T) {
+	v, buf := setupProgress()
+	defer func() {
+		progressNow = time.Now
+		progressRefresh = 50 * time.Millisecond
+		progressTick = rateInterval
+	}()
+	v.bars.live = false
+
+	a := v.AddProgress("a", 2)
+	b := v.AddProgress("b", 4)
+	a.Inc()
+	v.Println("log")
+	b.Done()
+	a.Inc()
+	v.Println("after")
+
+	expected := "log\n" +
+		"b [0/4] ----------   0% 0.0/s ETA 0s 0s\n" +
+		"a [2/2] ========== 100% 0.0/s ETA 0s 0s\n" +
+		"after\n"
+	if out := buf.String(); out != expected {
+		t.Errorf("incorrect output:\n%s", out)
+	}
+}
+
+func TestProgressThrottle(t *testing.T) {
+	v, buf := setupProgress()
+	defer func() {
+		progressNow = time.Now
+		progressRefresh = 50 * time.Millisecond
+		progressTick = rateInterval
+	}()
+	progressRefresh = time.Hour
+
+	a := v.AddProgress("a", 2)
+	a.Inc()
+	v.Println("first")
+	v.Println("second")
+	// the bar is hidden while the output is printed and only drawn again
+	// once the refresh interval has passed
+	expected := "a [0/2] ----------   0% 0.0/s ETA -- 0s\nfirst\nsecond\n"
+	if out := StripANSI(buf.String()); out != expected {
+		t.Errorf("incorrect output:\n%s", out)
+	}
+	a.Done()
+	expected += "a [1/2] =====-----  50% 0.0/s ETA 0s 0s\n"
+	if out := StripANSI(buf.String()); out != expected {
+		t.Errorf("incorrect output:\n%s", out)
+	}
+}
+
+func TestProgressConcurrent(t *testing.T) {
+	v, _ := setupProgress()
+	defer func() {
+		progressNow = time.Now
+		progressRefresh = 50 * time.Millisecond
+		progressTick = rateInterval
+	}()
+
+	// the progress functions do nothing before StartProgress
+	v.IncProgress()
+	v.SetProgress(2)
+	v.StopProgress()
+
+	bar := v.NewProgress(800)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				bar.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+	bar.Add(5)
+	bar.SetTotal(1000)
+	if bar.current != 800 || bar.total != 800 || !bar.done {
+		t.Errorf("incorrect progress: %d/%d", bar.current, bar.total)
+	}
+}
+
+func TestProgressRate(t *testing.T) {
+	current := time.Date(2017, 6, 1, 10, 30, 0, 0, time.Local)
+	progressNow = func() time.Time { return current }
+	defer func() { progressNow = time.Now }()
+
+	b := &ProgressBar{m: newProgressManager(nil), total: 1000, start: current, sampledAt: current}
+	for i, step := range []struct {
+		add      int
+		expected string
+	}{
+		{100, "[100/1000] =---------  10% 100/s ETA 9s 1s"},
+		{100, "[200/1000] ==--------  20% 100/s ETA 8s 2s"},
+		{200, "[400/1000] ====------  40% 130/s ETA 5s 3s"},
+		{0, "[400/1000] ====------  40% 91/s ETA 7s 4s"},
+	} {
+		current = current.Add(time.Second)
+		b.Add(step.add)
+		if res := b.render(0); res != step.expected {
+			t.Errorf("incorrect bar at step %d: %s", i, res)
+		}
+	}
+}
+
+func TestProgressStalled(t *testing.T) {
+	current := time.Date(2017, 6, 1, 10, 30, 0, 0, time.Local)
+	progressNow = func() time.Time { return current }
+	defer func() { progressNow = time.Now }()
+
+	b := &ProgressBar{m: newProgressManager(nil), total: 1000, start: current, sampledAt: current}
+	current = current.Add(time.Second)
+	b.Add(100)
+	// the rate falls while no progress is made, even though the bar is not
+	// changed
+	for i, step := range []struct {
+		wait     time.Duration
+		expected string
+	}{
+		{0, "[100/1000] =---------  10% 100/s ETA 9s 1s"},
+		{time.Second, "[100/1000] =---------  10% 70/s ETA 13s 2s"},
+		{2 * time.Second, "[100/1000] =---------  10% 49/s ETA 18s 4s"},
+	} {
+		current = current.Add(step.wait)
+		if res := b.render(0); res != step.expected {
+			t.Errorf("incorrect bar at step %d: %s", i, res)
+		}
+	}
+}
+
+func TestProgressTick(t *testing.T) {
+	v, buf := setupProgress()
+	var mu sync.Mutex
+	current := progressNow()
+	progressNow = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return current
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		current = current.Add(d)
+	}
+	progressTick = 5 * time.Millisecond
+	defer func() {
+		progressNow = time.Now
+		progressRefresh = 50 * time.Millisecond
+		progressTick = rateInterval
+	}()
+
+	bar := v.AddProgress("a", 1000)
+	advance(time.Second)
+	bar.Add(100)
+	// the stalled bar is redrawn without any changes
+	advance(2 * time.Second)
+	expected := "a [100/1000] =---------  10% 70/s ETA 13s 3s\n"
+	var out string
+	for wait := time.Now().Add(time.Second); time.Now().Before(wait); {
+		v.bars.mu.Lock()
+		out = StripANSI(buf.String())
+		v.bars.mu.Unlock()
+		if strings.HasSuffix(out, expected) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !strings.HasSuffix(out, expected) {
+		t.Errorf("stalled bar not redrawn:\n%s", out)
+	}
+
+	bar.Done()
+	v.bars.mu.Lock()
+	defer v.bars.mu.Unlock()
+	if v.bars.tick != nil {
+		t.Error("redraws not stopped once the bars are done")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for d, expected := range map[time.Duration]string{
+		400 * time.Millisecond:                       "0s",
+		45 * time.Second:                             "45s",
+		65 * time.Second:                             "1m05s",
+		2*time.Hour + 3*time.Minute + 20*time.Second: "2h03m",
+	} {
+		if res := formatDuration(d); res != expected {
+			t.Errorf("incorrect format for %v: %s", d, res)
+		}
+	}
+}
+
+func TestProgressStyleBar(t *testing.T) {
+	for i, c := range []struct {
+		style    ProgressStyle
+		percent  float64
+		width    int
+		expected string
+	}{
+		{ProgressStyle{}, 45, 10, "====------"},
+		{ProgressStyle{Fill: '#', Empty: '.', Head: '>'}, 45, 10, "####>....."},
+		{ProgressStyle{Head: '>'}, 100, 4, "===="},
+		{ProgressStyle{Blocks: true}, 30, 4, "█▎  "},
+		{ProgressStyle{Blocks: true}, 100, 3, "███"},
+		{ProgressStyle{}, -5, 4, "----"},
+		{ProgressStyle{Blocks: true}, -5, 4, "    "},
+		{ProgressStyle{Blocks: true}, 150, 2, "██"},
+		{ProgressStyle{FillColor: Green, EmptyColor: Red}, 50, 4,
+			Green.String() + "==" + Red.String() + "--" + ResetColor.String()},
+		{ProgressStyle{FillColor: Green}, 50, 4,
+			Green.String() + "==" + ResetColor.String() + "--"},
+	} {
+		s, err := compileProgressStyle(c.style)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res := s.bar(c.percent, c.width); res != c.expected {
+			t.Errorf("incorrect bar %d: %q", i, res)
+		}
+	}
+}
+
+func TestProgressNegative(t *testing.T) {
+	current := time.Date(2017, 6, 1, 10, 30, 0, 0, time.Local)
+	progressNow = func() time.Time { return current }
+	defer func() { progressNow = time.Now }()
+
+	b := &ProgressBar{m: newProgressManager(nil), total: 10, start: current, sampledAt: current}
+	b.Set(-5)
+	if res := b.render(0); res != "[-5/10] ----------   0% 0.0/s ETA -- 0s" {
+		t.Errorf("incorrect bar: %q", res)
+	}
+}
+
+func TestProgressStyleTemplate(t *testing.T) {
+	current := time.Date(2017, 6, 1, 10, 30, 0, 0, time.Local)
+	progressNow = func() time.Time { return current }
+	defer func() { progressNow = time.Now }()
+
+	b := &ProgressBar{m: newProgressManager(nil), label: "build", current: 45, total: 100,
+		start: current, sampledAt: current}
+	if err := b.SetStyle(ProgressStyle{Template: "{{.Label}}: {{.Bar}} {{.Percent}}"}); err != nil {
+		t.Fatal(err)
+	}
+	if res := b.render(0); res != "build: ====------  45%" {
+		t.Errorf("incorrect bar: %q", res)
+	}
+	// the bar fills the terminal, leaving the last column empty
+	if res := b.render(30); res != "build: =======----------  45%" {
+		t.Errorf("incorrect bar for terminal width: %q", res)
+	}
+	if err := b.SetStyle(ProgressStyle{Width: 4, Template: "{{.Bar}} {{.ETA}}"}); err != nil {
+		t.Fatal(err)
+	}
+	if res := b.render(30); res != "=--- --" {
+		t.Errorf("incorrect bar with fixed width: %q", res)
+	}
+
+	if err := b.SetStyle(ProgressStyle{Template: "{{.Bar"}); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+	if err := b.SetStyle(ProgressStyle{Template: "{{.Missing}}"}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+	if err := b.SetStyle(ProgressStyle{Template: "{{.Bar}} {{.Bar}}"}); err == nil {
+		t.Error("expected an error for a template with two bars")
+	}
+	if err := b.SetStyle(ProgressStyle{Width: 2, Template: "{{.Bar}}{{if .ETA}} {{.Bar}}{{end}}"}); err == nil {
+		t.Error("expected an error for a template with a conditional second bar")
+	}
+	if err := b.SetStyle(ProgressStyle{Width: 2, Template: "{{.Bar}}{{if eq .Current 45}} {{.Bar}}{{end}}"}); err != nil {
+		t.Fatal(err)
+	}
+	if res := b.render(0); res != "-- --" {
+		t.Errorf("incorrect bar with a conditional second bar: %q", res)
+	}
+}
@@ -2,9 +2,12 @@ package vox
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Prompt - Gets input from the input stream. By default Stdin. If an empty
@@ -14,18 +17,91 @@ func Prompt(name, defaultVal string) string { return v.Prompt(name, defaultVal)
 // Prompt - Gets input from the input stream. By default Stdin. If an empty
 // string is sent the default value will be returned.
 func (v *Vox) Prompt(name, defaultValue string) string {
-	reader := bufio.NewReader(v.in)
+	res, _ := v.PromptContext(context.Background(), name, defaultValue)
+	return res
+}
+
+// PromptContext - Gets input from the input stream like Prompt. If the context
+// is canceled or its deadline passes before a response is read, the default
+// value is returned with the context's error. If the input stream is closed
+// the default value is returned with io.EOF.
+func PromptContext(ctx context.Context, name, defaultVal string) (string, error) {
+	return v.PromptContext(ctx, name, defaultVal)
+}
+
+// PromptContext - Gets input from the input stream like Prompt. If the context
+// is canceled or its deadline passes before a response is read, the default
+// value is returned with the context's error. If the input stream is closed
+// the default value is returned with io.EOF.
+func (v *Vox) PromptContext(ctx context.Context, name, defaultValue string) (string, error) {
+	v.printPrompt(name, defaultValue)
+	input, err := v.readAnswer(ctx, name)
+	if err == ErrNoAnswer && defaultValue != "" {
+		return defaultValue, nil
+	}
+	if err != nil || input == "" {
+		return defaultValue, err
+	}
+	return input, nil
+}
+
+// printPrompt - Prints the name of a prompt and its default value.
+func (v *Vox) printPrompt(name, defaultValue string) {
 	if defaultValue != "" {
-		Printf("%s%s [%s]: %s", Yellow, name, defaultValue, ResetColor)
+		v.Printf("%s%s [%s]: %s", Yellow, name, defaultValue, ResetColor)
 	} else {
-		Printf("%s%s : %s", Yellow, name, ResetColor)
+		v.Printf("%s%s : %s", Yellow, name, ResetColor)
+	}
+}
+
+// readLine - Reads a line of input without the line ending. An error is only
+// returned if no input could be read.
+func readLine(reader *bufio.Reader) (string, error) {
+	input, err := reader.ReadString('\n')
+	if input != "" {
+		err = nil
+	}
+	return strings.TrimRight(input, "\r\n"), err
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+// readLineContext - Reads a line of input until the context is done. Reads
+// from the input stream cannot be interrupted, so a read that is still waiting
+// when the context is done is kept and its line is used by the next prompt.
+func (v *Vox) readLineContext(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	reader := v.reader
+	pending := v.pending
+	if pending == nil && ctx.Done() == nil {
+		v.mu.Unlock()
+		return readLine(reader)
 	}
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(input)
-	if input == "" && defaultValue != "" {
-		return defaultValue
+	if pending == nil {
+		pending = make(chan lineResult, 1)
+		go func() {
+			line, err := readLine(reader)
+			pending <- lineResult{line, err}
+		}()
+		v.pending = pending
+	}
+	v.mu.Unlock()
+
+	select {
+	case res := <-pending:
+		v.mu.Lock()
+		if v.pending == pending {
+			v.pending = nil
+		}
+		v.mu.Unlock()
+		return res.line, res.err
+	case <-ctx.Done():
+		v.Print("\n")
+		return "", ctx.Err()
 	}
-	return input
 }
 
 // PromptBool - Prompts the user for a boolean response.
@@ -35,28 +111,47 @@ func PromptBool(message string, defaultVal bool) bool {
 
 // PromptBool - Prompts the user for a boolean response.
 func (v *Vox) PromptBool(message string, defaultVal bool) bool {
+	res, _ := v.PromptBoolContext(context.Background(), message, defaultVal)
+	return res
+}
+
+// PromptBoolContext - Prompts the user for a boolean response like PromptBool.
+// Errors are returned as they are by PromptContext.
+func PromptBoolContext(ctx context.Context, message string, defaultVal bool) (bool, error) {
+	return v.PromptBoolContext(ctx, message, defaultVal)
+}
+
+// PromptBoolContext - Prompts the user for a boolean response like PromptBool.
+// Errors are returned as they are by PromptContext.
+func (v *Vox) PromptBoolContext(ctx context.Context, message string, defaultVal bool) (bool, error) {
 
 	defaultValStr := "Y"
 	if !defaultVal {
 		defaultValStr = "N"
 	}
 
-	Printf("%s%s [%s]: %s", Yellow, message, defaultValStr, ResetColor)
+	v.Printf("%s%s [%s]: %s", Yellow, message, defaultValStr, ResetColor)
 
-	reader := bufio.NewReader(v.in)
-	input, _ := reader.ReadString('\n')
-	input = strings.ToLower(strings.TrimSpace(input))
+	input, err := v.readAnswer(ctx, message)
+	if err == ErrNoAnswer {
+		return defaultVal, nil
+	}
+	if err != nil {
+		return defaultVal, err
+	}
+	input = strings.ToLower(input)
 	retValue := defaultVal
 
-	if input == "y" || input == "yes" {
+	// true and false are accepted for answers loaded from YAML or JSON
+	if input == "y" || input == "yes" || input == "true" {
 		retValue = true
 	}
 
-	if input == "n" || input == "no" {
+	if input == "n" || input == "no" || input == "false" {
 		retValue = false
 	}
 
-	return retValue
+	return retValue, nil
 }
 
 // PromptChoice - Prompts for a choice of a series of options from the user.
@@ -65,21 +160,411 @@ func PromptChoice(msg string, choices []string, defaultIdx int) string {
 }
 
 // PromptChoice - Prompts for a choice of a series of options from the user.
+// When the input and output are a terminal an interactive menu is shown,
+// otherwise the options are listed and chosen by their number. If the menu is
+// canceled an empty string is returned.
 func (v *Vox) PromptChoice(msg string, choices []string, defIdx int) string {
+	res, _ := v.PromptChoiceContext(context.Background(), msg, choices, defIdx)
+	return res
+}
+
+// PromptChoiceContext - Prompts for a choice of a series of options like
+// PromptChoice. Errors are returned as they are by PromptContext, and
+// ErrInterrupted is returned with an empty string if the menu is canceled. The
+// menu is not closed when the context is done, it is only checked before the
+// menu is shown.
+func PromptChoiceContext(ctx context.Context, msg string, choices []string, defaultIdx int) (string, error) {
+	return v.PromptChoiceContext(ctx, msg, choices, defaultIdx)
+}
+
+// PromptChoiceContext - Prompts for a choice of a series of options like
+// PromptChoice. Errors are returned as they are by PromptContext, and
+// ErrInterrupted is returned with an empty string if the menu is canceled. The
+// menu is not closed when the context is done, it is only checked before the
+// menu is shown.
+func (v *Vox) PromptChoiceContext(ctx context.Context, msg string, choices []string, defIdx int) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return choices[defIdx], err
+	}
+	if isTerminal(v.input()) && !v.scripted(msg) {
+		res, ok, err := v.selectMenu(msg, choices, []int{defIdx}, false)
+		if err != nil {
+			return "", err
+		}
+		if ok && len(res) > 0 {
+			return choices[res[0]], nil
+		}
+	}
 	output := []string{"Choose an option:"}
 	for idx, c := range choices {
 		output = append(output, fmt.Sprintf("%d. %s", idx+1, c))
 	}
-	Printf(strings.Join(output, "\n"))
-	Print(Yellow, "[", choices[defIdx], "] ", ResetColor)
-	reader := bufio.NewReader(v.in)
-	input, _ := reader.ReadString('\n')
+	v.Print(strings.Join(output, "\n"))
+	v.Print(Yellow, "[", choices[defIdx], "] ", ResetColor)
+	input, err := v.readAnswer(ctx, msg)
+	if err == ErrNoAnswer {
+		return choices[defIdx], nil
+	}
+	if err != nil {
+		return choices[defIdx], err
+	}
+	if idx := choiceIndex(choices, input); idx != -1 {
+		return choices[idx], nil
+	}
 	choice, err := strconv.Atoi(input)
 	if err != nil {
-		return choices[defIdx]
+		return choices[defIdx], nil
+	}
+	if choice > 0 && choice <= len(choices) {
+		return choices[choice-1], nil
+	}
+	return choices[defIdx], nil
+}
+
+// PromptMultiChoice - Prompts for any number of a series of options from the
+// user. Options are selected by their numbers, separated by commas, with ranges
+// such as 5-7. The words all and none select every option or no options. An
+// empty response selects the options in defaults, given by their index.
+func PromptMultiChoice(msg string, choices []string, defaults []int) []string {
+	return v.PromptMultiChoice(msg, choices, defaults)
+}
+
+// PromptMultiChoice - Prompts for any number of a series of options from the
+// user. Options are selected by their numbers, separated by commas, with ranges
+// such as 5-7. The words all and none select every option or no options. An
+// empty response selects the options in defaults, given by their index. When
+// the input and output are a terminal an interactive menu is shown instead. If
+// the menu is canceled nil is returned.
+func (v *Vox) PromptMultiChoice(msg string, choices []string, defaults []int) []string {
+	res, _ := v.PromptMultiChoiceContext(context.Background(), msg, choices, defaults)
+	return res
+}
+
+// PromptMultiChoiceContext - Prompts for any number of a series of options
+// like PromptMultiChoice. Errors are returned with the default options as they
+// are by PromptValidateContext, and ErrInterrupted is returned with nil if the
+// menu is canceled. The menu is not closed when the context is done, it is
+// only checked before the menu is shown.
+func PromptMultiChoiceContext(ctx context.Context, msg string, choices []string, defaults []int) ([]string, error) {
+	return v.PromptMultiChoiceContext(ctx, msg, choices, defaults)
+}
+
+// PromptMultiChoiceContext - Prompts for any number of a series of options
+// like PromptMultiChoice. Errors are returned with the default options as they
+// are by PromptValidateContext, and ErrInterrupted is returned with nil if the
+// menu is canceled. The menu is not closed when the context is done, it is
+// only checked before the menu is shown.
+func (v *Vox) PromptMultiChoiceContext(ctx context.Context, msg string, choices []string, defaults []int) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		res := []string{}
+		for _, idx := range defaults {
+			res = append(res, choices[idx])
+		}
+		return res, err
+	}
+	if isTerminal(v.input()) && !v.scripted(msg) {
+		selected, ok, err := v.selectMenu(msg, choices, defaults, true)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res := []string{}
+			for _, idx := range selected {
+				res = append(res, choices[idx])
+			}
+			return res, nil
+		}
+	}
+	for idx, c := range choices {
+		v.Printf("%d. %s\n", idx+1, c)
+	}
+	def := "none"
+	if len(defaults) > 0 {
+		nums := make([]string, len(defaults))
+		for i, idx := range defaults {
+			nums[i] = strconv.Itoa(idx + 1)
+		}
+		def = strings.Join(nums, ",")
+	}
+	input, err := v.PromptValidateContext(ctx, msg, def, func(s string) error {
+		_, err := parseSelection(s, choices)
+		return err
+	})
+	selected, _ := parseSelection(input, choices)
+	res := []string{}
+	for _, idx := range selected {
+		res = append(res, choices[idx])
+	}
+	return res, err
+}
+
+// choiceIndex - Returns the index of the choice matching s, ignoring case, or -1
+// if there is none.
+func choiceIndex(choices []string, s string) int {
+	for idx, c := range choices {
+		if strings.EqualFold(c, s) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// parseSelection - Parses a list of option numbers, ranges and names, such as
+// 1,3,5-7, into the indexes of the selected options in order.
+func parseSelection(s string, choices []string) ([]int, error) {
+	n := len(choices)
+	selected := make([]bool, n)
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "all":
+		for i := range selected {
+			selected[i] = true
+		}
+	case "none":
+	default:
+		for _, part := range strings.Split(s, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			if idx := choiceIndex(choices, part); idx != -1 {
+				selected[idx] = true
+				continue
+			}
+			lo, hi := part, part
+			if i := strings.Index(part, "-"); i > 0 {
+				lo, hi = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+			}
+			first, err := strconv.Atoi(lo)
+			if err != nil {
+				return nil, fmt.Errorf("%s is not an option number", lo)
+			}
+			last, err := strconv.Atoi(hi)
+			if err != nil {
+				return nil, fmt.Errorf("%s is not an option number", hi)
+			}
+			if first > last {
+				return nil, fmt.Errorf("%s is not a valid range", part)
+			}
+			if first < 1 || last > n {
+				return nil, fmt.Errorf("%s is not between 1 and %d", part, n)
+			}
+			for i := first; i <= last; i++ {
+				selected[i-1] = true
+			}
+		}
+	}
+	var res []int
+	for i, ok := range selected {
+		if ok {
+			res = append(res, i)
+		}
+	}
+	return res, nil
+}
+
+// DateFormat is the layout used to read and display dates in PromptDate.
+const DateFormat = "2006-01-02"
+
+// ErrTooManyAttempts is returned by validated prompts when no valid value was
+// given in the number of attempts set with SetMaxAttempts.
+var ErrTooManyAttempts = errors.New("vox: too many invalid attempts")
+
+// SetMaxAttempts - Sets how many times a validated prompt asks for a value
+// before giving up and returning the default. The Context variants also return
+// ErrTooManyAttempts. If zero, which is the default, the prompt asks until a
+// valid value is given or the input is closed.
+func SetMaxAttempts(n int) { v.SetMaxAttempts(n) }
+
+// SetMaxAttempts - Sets how many times a validated prompt asks for a value
+// before giving up and returning the default. The Context variants also return
+// ErrTooManyAttempts. If zero, which is the default, the prompt asks until a
+// valid value is given or the input is closed.
+func (v *Vox) SetMaxAttempts(n int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.maxAttempts = n
+}
+
+// PromptValidate - Prompts for a value and checks it with the validate
+// function. If the value is not valid the error is printed using Error and the
+// prompt is repeated. An empty response uses the default value, which is also
+// validated.
+func PromptValidate(name, defaultVal string, validate func(string) error) string {
+	return v.PromptValidate(name, defaultVal, validate)
+}
+
+// PromptValidate - Prompts for a value and checks it with the validate
+// function. If the value is not valid the error is printed using Error and the
+// prompt is repeated. An empty response uses the default value, which is also
+// validated.
+func (v *Vox) PromptValidate(name, defaultVal string, validate func(string) error) string {
+	res, _ := v.PromptValidateContext(context.Background(), name, defaultVal, validate)
+	return res
+}
+
+// PromptValidateContext - Prompts for a value and checks it like
+// PromptValidate. Errors are returned as they are by PromptContext.
+func PromptValidateContext(ctx context.Context, name, defaultVal string, validate func(string) error) (string, error) {
+	return v.PromptValidateContext(ctx, name, defaultVal, validate)
+}
+
+// PromptValidateContext - Prompts for a value and checks it like
+// PromptValidate. Errors are returned as they are by PromptContext.
+func (v *Vox) PromptValidateContext(ctx context.Context, name, defaultVal string, validate func(string) error) (string, error) {
+	v.mu.Lock()
+	maxAttempts := v.maxAttempts
+	v.mu.Unlock()
+
+	// answers from a source are the same each time, so they are not repeated
+	_, answered := v.lookupAnswer(name)
+	for attempt := 1; ; attempt++ {
+		v.printPrompt(name, defaultVal)
+		input, err := v.readAnswer(ctx, name)
+		if err != nil && err != ErrNoAnswer {
+			return defaultVal, err
+		}
+		noAnswer := err == ErrNoAnswer
+		if input == "" {
+			input = defaultVal
+		}
+		err = validate(input)
+		if err == nil {
+			return input, nil
+		}
+		if noAnswer {
+			return defaultVal, ErrNoAnswer
+		}
+		v.Error(err.Error())
+		if answered {
+			return defaultVal, err
+		}
+		if maxAttempts > 0 && attempt >= maxAttempts {
+			return defaultVal, ErrTooManyAttempts
+		}
 	}
-	if choice < len(choices) {
-		return choices[choice-1]
+}
+
+// PromptInt - Prompts for a whole number.
+func PromptInt(name string, defaultVal int) int { return v.PromptInt(name, defaultVal) }
+
+// PromptInt - Prompts for a whole number.
+func (v *Vox) PromptInt(name string, defaultVal int) int {
+	res, _ := v.PromptIntContext(context.Background(), name, defaultVal)
+	return res
+}
+
+// PromptIntContext - Prompts for a whole number like PromptInt. Errors are
+// returned as they are by PromptValidateContext.
+func PromptIntContext(ctx context.Context, name string, defaultVal int) (int, error) {
+	return v.PromptIntContext(ctx, name, defaultVal)
+}
+
+// PromptIntContext - Prompts for a whole number like PromptInt. Errors are
+// returned as they are by PromptValidateContext.
+func (v *Vox) PromptIntContext(ctx context.Context, name string, defaultVal int) (int, error) {
+	s, err := v.PromptValidateContext(ctx, name, strconv.Itoa(defaultVal), func(s string) error {
+		if _, err := strconv.Atoi(s); err != nil {
+			return fmt.Errorf("%s is not a whole number", s)
+		}
+		return nil
+	})
+	res, _ := strconv.Atoi(s)
+	return res, err
+}
+
+// PromptFloat - Prompts for a number.
+func PromptFloat(name string, defaultVal float64) float64 {
+	return v.PromptFloat(name, defaultVal)
+}
+
+// PromptFloat - Prompts for a number.
+func (v *Vox) PromptFloat(name string, defaultVal float64) float64 {
+	res, _ := v.PromptFloatContext(context.Background(), name, defaultVal)
+	return res
+}
+
+// PromptFloatContext - Prompts for a number like PromptFloat. Errors are
+// returned as they are by PromptValidateContext.
+func PromptFloatContext(ctx context.Context, name string, defaultVal float64) (float64, error) {
+	return v.PromptFloatContext(ctx, name, defaultVal)
+}
+
+// PromptFloatContext - Prompts for a number like PromptFloat. Errors are
+// returned as they are by PromptValidateContext.
+func (v *Vox) PromptFloatContext(ctx context.Context, name string, defaultVal float64) (float64, error) {
+	def := strconv.FormatFloat(defaultVal, 'f', -1, 64)
+	s, err := v.PromptValidateContext(ctx, name, def, func(s string) error {
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return fmt.Errorf("%s is not a number", s)
+		}
+		return nil
+	})
+	res, _ := strconv.ParseFloat(s, 64)
+	return res, err
+}
+
+// PromptDuration - Prompts for a duration such as 1h30m.
+func PromptDuration(name string, defaultVal time.Duration) time.Duration {
+	return v.PromptDuration(name, defaultVal)
+}
+
+// PromptDuration - Prompts for a duration such as 1h30m.
+func (v *Vox) PromptDuration(name string, defaultVal time.Duration) time.Duration {
+	res, _ := v.PromptDurationContext(context.Background(), name, defaultVal)
+	return res
+}
+
+// PromptDurationContext - Prompts for a duration like PromptDuration. Errors
+// are returned as they are by PromptValidateContext.
+func PromptDurationContext(ctx context.Context, name string, defaultVal time.Duration) (time.Duration, error) {
+	return v.PromptDurationContext(ctx, name, defaultVal)
+}
+
+// PromptDurationContext - Prompts for a duration like PromptDuration. Errors
+// are returned as they are by PromptValidateContext.
+func (v *Vox) PromptDurationContext(ctx context.Context, name string, defaultVal time.Duration) (time.Duration, error) {
+	s, err := v.PromptValidateContext(ctx, name, defaultVal.String(), func(s string) error {
+		if _, err := time.ParseDuration(s); err != nil {
+			return fmt.Errorf("%s is not a duration, such as 1h30m", s)
+		}
+		return nil
+	})
+	res, _ := time.ParseDuration(s)
+	return res, err
+}
+
+// PromptDate - Prompts for a date in the DateFormat layout. If the default
+// value is the zero time there is no default.
+func PromptDate(name string, defaultVal time.Time) time.Time {
+	return v.PromptDate(name, defaultVal)
+}
+
+// PromptDate - Prompts for a date in the DateFormat layout. If the default
+// value is the zero time there is no default.
+func (v *Vox) PromptDate(name string, defaultVal time.Time) time.Time {
+	res, _ := v.PromptDateContext(context.Background(), name, defaultVal)
+	return res
+}
+
+// PromptDateContext - Prompts for a date like PromptDate. Errors are returned
+// as they are by PromptValidateContext.
+func PromptDateContext(ctx context.Context, name string, defaultVal time.Time) (time.Time, error) {
+	return v.PromptDateContext(ctx, name, defaultVal)
+}
+
+// PromptDateContext - Prompts for a date like PromptDate. Errors are returned
+// as they are by PromptValidateContext.
+func (v *Vox) PromptDateContext(ctx context.Context, name string, defaultVal time.Time) (time.Time, error) {
+	var def string
+	if !defaultVal.IsZero() {
+		def = defaultVal.Format(DateFormat)
 	}
-	return choices[defIdx]
+	s, err := v.PromptValidateContext(ctx, name, def, func(s string) error {
+		if _, err := time.ParseInLocation(DateFormat, s, time.Local); err != nil {
+			return fmt.Errorf("%s is not a date, such as %s", s, DateFormat)
+		}
+		return nil
+	})
+	res, _ := time.ParseInLocation(DateFormat, s, time.Local)
+	return res, err
 }
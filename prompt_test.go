@@ -1,9 +1,14 @@
 package vox
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPrompt(t *testing.T) {
@@ -101,3 +106,175 @@ func TestPromptChoice(t *testing.T) {
 	pipeline.Clear()
 	ClearInput()
 }
+
+func TestPromptSecret(t *testing.T) {
+	pipeline.Clear()
+	ClearInput()
+	SendInput("s3cret\n")
+	result := PromptSecret("password")
+	if result != "s3cret" {
+		t.Errorf("Prompt response not valid: '%s'", result)
+	}
+	expected := fmt.Sprintf("%s%s : %s", Yellow, "password", ResetColor)
+	if pipeline.All() != expected {
+		t.Errorf("response not correct: %s", pipeline.All())
+	}
+	pipeline.Clear()
+}
+
+func TestPromptValidate(t *testing.T) {
+	pipeline.Clear()
+	ClearInput()
+	SendInput("abc\n42\n")
+	if res := PromptInt("count", 5); res != 42 {
+		t.Errorf("Prompt response not valid: %d", res)
+	}
+	if !strings.Contains(pipeline.All(), "abc is not a whole number") {
+		t.Errorf("error not printed: %s", pipeline.All())
+	}
+
+	ClearInput()
+	SendInput("\n")
+	if res := PromptFloat("ratio", 0.5); res != 0.5 {
+		t.Errorf("Prompt response not valid: %v", res)
+	}
+
+	ClearInput()
+	SendInput("1h30m\n")
+	if res := PromptDuration("timeout", time.Minute); res != 90*time.Minute {
+		t.Errorf("Prompt response not valid: %v", res)
+	}
+
+	ClearInput()
+	SendInput("2020-02-30\n2020-02-29\n")
+	res := PromptDate("date", time.Time{})
+	if res.Format(DateFormat) != "2020-02-29" {
+		t.Errorf("Prompt response not valid: %v", res)
+	}
+
+	SetMaxAttempts(2)
+	defer SetMaxAttempts(0)
+	ClearInput()
+	SendInput("a\nb\nc\n")
+	if res := PromptInt("count", 5); res != 5 {
+		t.Errorf("default not used after max attempts: %d", res)
+	}
+	ClearInput()
+	SendInput("a\nb\n")
+	n, err := PromptIntContext(context.Background(), "count", 5)
+	if n != 5 || err != ErrTooManyAttempts {
+		t.Errorf("incorrect result after max attempts: %d %v", n, err)
+	}
+	pipeline.Clear()
+	ClearInput()
+}
+
+func TestPromptMultiChoice(t *testing.T) {
+	choices := []string{"dev", "test", "stage", "prod"}
+	for input, expected := range map[string][]string{
+		"1,3-4\n": {"dev", "stage", "prod"},
+		"all\n":   {"dev", "test", "stage", "prod"},
+		"none\n":  {},
+		"\n":      {"test"},
+		" 2 ,2\n": {"test"},
+	} {
+		pipeline.Clear()
+		ClearInput()
+		SendInput(input)
+		res := PromptMultiChoice("Environments", choices, []int{1})
+		if fmt.Sprint(res) != fmt.Sprint(expected) {
+			t.Errorf("incorrect selection for %q: %v", input, res)
+		}
+	}
+
+	pipeline.Clear()
+	ClearInput()
+	SendInput("5\n4-2\n2\n")
+	res := PromptMultiChoice("Environments", choices, nil)
+	if fmt.Sprint(res) != "[test]" {
+		t.Errorf("incorrect selection: %v", res)
+	}
+	if !strings.Contains(pipeline.All(), "4-2 is not a valid range") {
+		t.Errorf("error not printed: %s", pipeline.All())
+	}
+	pipeline.Clear()
+	ClearInput()
+}
+
+func TestPromptContext(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	SetInput(r)
+	defer ClearInput()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	res, err := PromptContext(ctx, "name", "default")
+	if res != "default" || err != context.DeadlineExceeded {
+		t.Errorf("incorrect result after timeout: %q %v", res, err)
+	}
+
+	// the waiting read is used by the next prompt
+	io.WriteString(w, "late\n")
+	if res := Prompt("name", "default"); res != "late" {
+		t.Errorf("pending input not used: %q", res)
+	}
+
+	w.Close()
+	res, err = PromptContext(context.Background(), "name", "default")
+	if res != "default" || err != io.EOF {
+		t.Errorf("incorrect result at end of input: %q %v", res, err)
+	}
+	if ok, err := PromptBoolContext(context.Background(), "sure", true); !ok || err != io.EOF {
+		t.Errorf("incorrect result at end of input: %v %v", ok, err)
+	}
+	choices := []string{"a", "b", "c"}
+	if res, err := PromptChoiceContext(context.Background(), "pick", choices, 1); res != "b" || err != io.EOF {
+		t.Errorf("incorrect choice at end of input: %q %v", res, err)
+	}
+	res2, err := PromptMultiChoiceContext(context.Background(), "pick", choices, []int{0, 2})
+	if strings.Join(res2, ",") != "a,c" || err != io.EOF {
+		t.Errorf("incorrect choices at end of input: %v %v", res2, err)
+	}
+	if res, err := PromptSecretContext(context.Background(), "token"); res != "" || err != io.EOF {
+		t.Errorf("incorrect secret at end of input: %q %v", res, err)
+	}
+	if res, err := PromptIntContext(context.Background(), "port", 80); res != 80 || err != io.EOF {
+		t.Errorf("incorrect number at end of input: %v %v", res, err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if res, err := PromptChoiceContext(canceled, "pick", choices, 2); res != "c" || err != context.Canceled {
+		t.Errorf("incorrect choice after cancel: %q %v", res, err)
+	}
+	pipeline.Clear()
+}
+
+func TestPromptSharedInput(t *testing.T) {
+	ClearInput()
+	SendInput("first\nsecond\n")
+	SendInput("y\n")
+	if res := Prompt("one", ""); res != "first" {
+		t.Errorf("incorrect first response: %q", res)
+	}
+	if res := Prompt("two", ""); res != "second" {
+		t.Errorf("incorrect second response: %q", res)
+	}
+	if res := PromptBool("three", false); !res {
+		t.Error("incorrect third response")
+	}
+
+	SetInput(strings.NewReader("1h\n3\n"))
+	if res := PromptDuration("four", 0); res != time.Hour {
+		t.Errorf("incorrect fourth response: %v", res)
+	}
+	if res := PromptInt("five", 0); res != 3 {
+		t.Errorf("incorrect fifth response: %v", res)
+	}
+	pipeline.Clear()
+	ClearInput()
+}
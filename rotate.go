@@ -0,0 +1,227 @@
+package vox
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// timeNow is used by the RotatingFilePipeline to determine when to rotate. It
+// can be replaced during tests.
+var timeNow = time.Now
+
+// backupTimeFormat is the timestamp added to the name of rotated files. It
+// sorts in the order the files were rotated.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFilePipeline sends output into a local file and rotates that file
+// when it grows too large or when the day changes. Rotated files are renamed
+// with the time they were started, for example
+// app-2017-06-01T10-30-00.000.log.
+type RotatingFilePipeline struct {
+	Filepath string
+	MinLevel Level
+	// MaxSize - The size in bytes at which the file is rotated. If zero the file
+	// is not rotated by size.
+	MaxSize int64
+	// Daily - If true the file is rotated on the first write of each day.
+	Daily bool
+	// MaxBackups - The number of rotated files to keep. If zero all rotated
+	// files are kept.
+	MaxBackups int
+	// Compress - If true rotated files are compressed with gzip.
+	Compress bool
+
+	file     afero.File
+	size     int64
+	openedAt time.Time
+}
+
+// Config returns the pipline configuration
+func (r *RotatingFilePipeline) Config() *PipelineConfig {
+	return &PipelineConfig{
+		Plain:    true,
+		MinLevel: r.MinLevel,
+	}
+}
+
+// Initialize opens the local file for writing
+func (r *RotatingFilePipeline) Initialize() error {
+	return r.open()
+}
+
+// Close closes the file pointer
+func (r *RotatingFilePipeline) Close() {
+	if r.file != nil {
+		r.file.Close()
+	}
+}
+
+// Write sends the data to the local filepath, rotating the file first if
+// needed.
+func (r *RotatingFilePipeline) Write(b []byte) (int, error) {
+	if r.file == nil {
+		// the file could not be reopened after rotating
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	var rerr error
+	if r.shouldRotate(len(b)) {
+		// the data is still written if rotating fails, as long as a file is
+		// open
+		rerr = r.Rotate()
+		if r.file == nil {
+			return 0, rerr
+		}
+	}
+	num, err := r.file.Write(b)
+	r.size += int64(num)
+	if num > 0 {
+		r.file.Sync()
+	}
+	if err == nil {
+		err = rerr
+	}
+	return num, err
+}
+
+// Rotate renames the current file, opens a new file, and then compresses the
+// renamed file if required and removes old backups. The log file is reopened
+// even if rotating fails, so that later writes can try again.
+func (r *RotatingFilePipeline) Rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	openedAt := r.openedAt
+	backup := r.backupName(openedAt)
+	if err := fs.Rename(r.Filepath, backup); err != nil {
+		// the same file is reopened, and keeps the time it was first opened
+		r.open()
+		r.openedAt = openedAt
+		return err
+	}
+	err := r.open()
+	if r.Compress {
+		if cerr := compressFile(backup); err == nil {
+			err = cerr
+		}
+	}
+	if rerr := r.removeOldBackups(); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+func (r *RotatingFilePipeline) open() error {
+	f, err := fs.OpenFile(r.Filepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0700)
+	if err != nil {
+		r.file = nil
+		return err
+	}
+	r.file = f
+	info, err := r.file.Stat()
+	if err != nil {
+		return err
+	}
+	r.size = info.Size()
+	r.openedAt = timeNow()
+	if r.size > 0 {
+		r.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (r *RotatingFilePipeline) shouldRotate(n int) bool {
+	if r.MaxSize > 0 && r.size > 0 && r.size+int64(n) > r.MaxSize {
+		return true
+	}
+	if r.Daily && r.size > 0 {
+		y1, m1, d1 := r.openedAt.Date()
+		y2, m2, d2 := timeNow().Date()
+		return y1 != y2 || m1 != m2 || d1 != d2
+	}
+	return false
+}
+
+// backupName - Builds the name of a rotated file by adding a timestamp between
+// the base name and extension of the log file.
+func (r *RotatingFilePipeline) backupName(t time.Time) string {
+	prefix, ext := r.backupParts()
+	return prefix + t.Format(backupTimeFormat) + ext
+}
+
+func (r *RotatingFilePipeline) backupParts() (string, string) {
+	ext := filepath.Ext(r.Filepath)
+	return strings.TrimSuffix(r.Filepath, ext) + "-", ext
+}
+
+// removeOldBackups - Deletes the oldest rotated files so that only MaxBackups
+// remain.
+func (r *RotatingFilePipeline) removeOldBackups() error {
+	if r.MaxBackups <= 0 {
+		return nil
+	}
+	prefix, ext := r.backupParts()
+	dir := filepath.Dir(r.Filepath)
+	infos, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return err
+	}
+	var backups []string
+	for _, info := range infos {
+		name := filepath.Join(dir, info.Name())
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		// other files sharing the prefix, such as app-worker.log, are not
+		// backups
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz")
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, strings.TrimSuffix(stamp, ext)); err == nil {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+	for len(backups) > r.MaxBackups {
+		if err := fs.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// compressFile - Writes a gzipped copy of a file with a .gz extension and
+// removes the original.
+func compressFile(path string) error {
+	src, err := fs.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := fs.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0700)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	src.Close()
+	return fs.Remove(path)
+}
@@ -0,0 +1,246 @@
+package vox
+
+import (
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func setupRotation(t *testing.T) func(time.Duration) {
+	fs = afero.NewMemMapFs()
+	if err := fs.MkdirAll("/var/logs", 0700); err != nil {
+		t.Fatal(err.Error())
+	}
+	current := time.Date(2017, 6, 1, 10, 30, 0, 0, time.Local)
+	timeNow = func() time.Time { return current }
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+func logFiles(t *testing.T) []string {
+	infos, err := afero.ReadDir(fs, "/var/logs")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotatingFilePipelineSize(t *testing.T) {
+	advance := setupRotation(t)
+	defer func() { timeNow = time.Now }()
+
+	pipeline := &RotatingFilePipeline{
+		Filepath:   "/var/logs/app.log",
+		MaxSize:    10,
+		MaxBackups: 2,
+	}
+	v := New()
+	v.SetPipelines(pipeline)
+	for _, line := range []string{"first", "second", "third", "fourth"} {
+		v.Println(line)
+		advance(time.Second)
+	}
+
+	expected := []string{
+		"app-2017-06-01T10-30-01.000.log",
+		"app-2017-06-01T10-30-02.000.log",
+		"app.log",
+	}
+	files := logFiles(t)
+	if len(files) != len(expected) {
+		t.Fatalf("incorrect files: %v", files)
+	}
+	for i := range files {
+		if files[i] != expected[i] {
+			t.Errorf("incorrect files: %v", files)
+		}
+	}
+	b, _ := afero.ReadFile(fs, "/var/logs/app-2017-06-01T10-30-02.000.log")
+	if string(b) != "third\n" {
+		t.Errorf("data missmatch: %s", string(b))
+	}
+	b, _ = afero.ReadFile(fs, "/var/logs/app.log")
+	if string(b) != "fourth\n" {
+		t.Errorf("data missmatch: %s", string(b))
+	}
+}
+
+func TestRotatingFilePipelineDaily(t *testing.T) {
+	advance := setupRotation(t)
+	defer func() { timeNow = time.Now }()
+
+	pipeline := &RotatingFilePipeline{
+		Filepath: "/var/logs/app.log",
+		Daily:    true,
+		Compress: true,
+	}
+	v := New()
+	v.SetPipelines(pipeline)
+	v.Println("monday")
+	v.Println("still monday")
+	advance(24 * time.Hour)
+	v.Println("tuesday")
+
+	files := logFiles(t)
+	if len(files) != 2 || files[0] != "app-2017-06-01T10-30-00.000.log.gz" {
+		t.Fatalf("incorrect files: %v", files)
+	}
+	f, err := fs.Open("/var/logs/" + files[0])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	b, _ := ioutil.ReadAll(gz)
+	if string(b) != "monday\nstill monday\n" {
+		t.Errorf("data missmatch: %s", string(b))
+	}
+}
+
+func TestRotatingFilePipelineSiblings(t *testing.T) {
+	advance := setupRotation(t)
+	defer func() { timeNow = time.Now }()
+
+	for _, name := range []string{"app-worker.log", "app-2017-05-31T09-00-00.000.log"} {
+		if err := afero.WriteFile(fs, "/var/logs/"+name, []byte("x\n"), 0700); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	pipeline := &RotatingFilePipeline{
+		Filepath:   "/var/logs/app.log",
+		MaxSize:    10,
+		MaxBackups: 1,
+	}
+	v := New()
+	v.SetPipelines(pipeline)
+	v.Println("first")
+	advance(time.Second)
+	v.Println("second")
+
+	expected := []string{"app-2017-06-01T10-30-00.000.log", "app-worker.log", "app.log"}
+	files := logFiles(t)
+	if len(files) != len(expected) {
+		t.Fatalf("incorrect files: %v", files)
+	}
+	for i := range files {
+		if files[i] != expected[i] {
+			t.Errorf("incorrect files: %v", files)
+		}
+	}
+}
+
+// renameFailFs - A filesystem where renaming files fails while fail is set.
+type renameFailFs struct {
+	afero.Fs
+	fail bool
+}
+
+func (f *renameFailFs) Rename(oldname, newname string) error {
+	if f.fail {
+		return errors.New("rename failed")
+	}
+	return f.Fs.Rename(oldname, newname)
+}
+
+func TestRotatingFilePipelineFailure(t *testing.T) {
+	current := time.Date(2017, 6, 1, 10, 30, 0, 0, time.Local)
+	timeNow = func() time.Time { return current }
+	defer func() { timeNow = time.Now }()
+	dir, err := ioutil.TempDir("", "vox")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	// the os filesystem fails when a file is closed twice
+	failFs := &renameFailFs{Fs: afero.NewOsFs(), fail: true}
+	fs = failFs
+
+	pipeline := &RotatingFilePipeline{
+		Filepath: filepath.Join(dir, "app.log"),
+		MaxSize:  10,
+	}
+	if err := pipeline.Initialize(); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer pipeline.Close()
+	if _, err := pipeline.Write([]byte("first\n")); err != nil {
+		t.Fatal(err.Error())
+	}
+	if n, err := pipeline.Write([]byte("second\n")); err == nil || n != 7 {
+		t.Errorf("expected the rotation to fail after writing: %d %v", n, err)
+	}
+	failFs.fail = false
+	if _, err := pipeline.Write([]byte("third\n")); err != nil {
+		t.Fatalf("write after a failed rotation: %s", err)
+	}
+
+	b, _ := afero.ReadFile(fs, filepath.Join(dir, "app-2017-06-01T10-30-00.000.log"))
+	if string(b) != "first\nsecond\n" {
+		t.Errorf("data missmatch: %s", string(b))
+	}
+	b, _ = afero.ReadFile(fs, filepath.Join(dir, "app.log"))
+	if string(b) != "third\n" {
+		t.Errorf("data missmatch: %s", string(b))
+	}
+}
+
+// gzipFailFs - A filesystem where compressed files cannot be created.
+type gzipFailFs struct {
+	afero.Fs
+}
+
+func (f *gzipFailFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if strings.HasSuffix(name, ".gz") {
+		return nil, errors.New("open failed")
+	}
+	return f.Fs.OpenFile(name, flag, perm)
+}
+
+func TestRotatingFilePipelineCompressFailure(t *testing.T) {
+	advance := setupRotation(t)
+	defer func() { timeNow = time.Now }()
+	fs = &gzipFailFs{Fs: fs}
+
+	pipeline := &RotatingFilePipeline{
+		Filepath: "/var/logs/app.log",
+		MaxSize:  10,
+		Compress: true,
+	}
+	if err := pipeline.Initialize(); err != nil {
+		t.Fatal(err.Error())
+	}
+	defer pipeline.Close()
+	for _, line := range []string{"first", "second", "third", "fourth"} {
+		if n, _ := pipeline.Write([]byte(line + "\n")); n != len(line)+1 {
+			t.Errorf("%s was not written", line)
+		}
+		advance(time.Second)
+	}
+
+	// each backup keeps its own name even though it was not compressed
+	for name, expected := range map[string]string{
+		"app-2017-06-01T10-30-00.000.log": "first\n",
+		"app-2017-06-01T10-30-01.000.log": "second\n",
+		"app-2017-06-01T10-30-02.000.log": "third\n",
+		"app.log":                         "fourth\n",
+	} {
+		b, _ := afero.ReadFile(fs, "/var/logs/"+name)
+		if string(b) != expected {
+			t.Errorf("data missmatch in %s: %s", name, string(b))
+		}
+	}
+}
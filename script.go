@@ -0,0 +1,165 @@
+package vox
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Script - A test helper for interactive flows. A script lists the prompts
+// that are expected, in order, with the answer to give each one, and output
+// that is expected between them. Prompts are answered by the script instead
+// of the input stream, and the test fails if a prompt is asked out of order,
+// an expected prompt is never asked or expected output is not printed.
+//
+//	s := vox.NewScript(t)
+//	s.Expect("Enter a file").Answer("test.txt")
+//	s.ExpectOutput("Config file read")
+//	s.Run(readConfig)
+type Script struct {
+	// Pipeline - Receives all output while the script is used.
+	Pipeline *TestPipeline
+
+	mu     sync.Mutex
+	t      testing.TB
+	steps  []*Expectation
+	next   int
+	cursor int
+	done   bool
+}
+
+// Expectation - A prompt or output expected by a Script.
+type Expectation struct {
+	script *Script
+	prompt string
+	answer string
+	output string
+	// isOutput - True for output, which has no prompt.
+	isOutput bool
+}
+
+// NewScript - Creates a script for the default Vox instance. Output is sent to
+// the script's Pipeline until the test finishes, when the script also checks
+// that all expectations were met and the previous pipelines and input stream
+// are restored.
+func NewScript(t testing.TB) *Script { return v.NewScript(t) }
+
+// NewScript - Creates a script for the Vox instance. Output is sent to the
+// script's Pipeline until the test finishes, when the script also checks that
+// all expectations were met and the previous pipelines and input stream are
+// restored.
+func (v *Vox) NewScript(t testing.TB) *Script {
+	v.mu.Lock()
+	pipelines, in := v.pipelines, v.in
+	v.mu.Unlock()
+	s := &Script{
+		Pipeline: v.Test(),
+		t:        t,
+	}
+	v.mu.Lock()
+	v.script = s
+	v.mu.Unlock()
+	t.Cleanup(func() {
+		s.Done()
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if v.script == s {
+			v.script = nil
+		}
+		v.pipelines = pipelines
+		v.setInput(in)
+	})
+	return s
+}
+
+// Expect - Adds a prompt that is expected next. The prompt is matched by its
+// name or message.
+func (s *Script) Expect(prompt string) *Expectation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := &Expectation{script: s, prompt: prompt}
+	s.steps = append(s.steps, e)
+	return e
+}
+
+// Answer - Sets the answer given to the prompt. Without an answer the prompt
+// receives an empty response and uses its default value.
+func (e *Expectation) Answer(answer string) *Script {
+	e.script.mu.Lock()
+	defer e.script.mu.Unlock()
+	e.answer = answer
+	return e.script
+}
+
+// ExpectOutput - Adds text that is expected to be printed after the previous
+// expectations and before the next prompt. Escape sequences are ignored.
+func (s *Script) ExpectOutput(text string) *Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = append(s.steps, &Expectation{script: s, output: text, isOutput: true})
+	return s
+}
+
+// Run - Calls fn and then checks that all expectations were met.
+func (s *Script) Run(fn func()) {
+	s.t.Helper()
+	fn()
+	s.Done()
+}
+
+// Done - Checks that all expectations were met. It is called automatically
+// when the test finishes.
+func (s *Script) Done() {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	s.checkOutput("the end of the script")
+	for ; s.next < len(s.steps); s.next++ {
+		s.t.Errorf("expected prompt %q was not asked", s.steps[s.next].prompt)
+		s.checkOutput("the end of the script")
+	}
+}
+
+// answer - Returns the answer for a prompt, or fails the test if the prompt
+// was not expected next.
+func (s *Script) answer(prompt string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkOutput("prompt " + prompt)
+	if s.next >= len(s.steps) {
+		s.t.Errorf("unexpected prompt %q", prompt)
+		return "", ErrNoAnswer
+	}
+	e := s.steps[s.next]
+	if e.prompt != prompt {
+		s.t.Errorf("prompt %q was asked, expected %q", prompt, e.prompt)
+		return "", ErrNoAnswer
+	}
+	s.next++
+	s.cursor = len(StripANSI(s.Pipeline.All()))
+	return e.answer, nil
+}
+
+// checkOutput - Checks the output expected before the next prompt against the
+// output printed since the last one. The lock must be held.
+func (s *Script) checkOutput(before string) {
+	out := StripANSI(s.Pipeline.All())
+	if s.cursor > len(out) {
+		// the pipeline was cleared
+		s.cursor = 0
+	}
+	for ; s.next < len(s.steps) && s.steps[s.next].isOutput; s.next++ {
+		text := s.steps[s.next].output
+		i := strings.Index(out[s.cursor:], text)
+		if i == -1 {
+			s.t.Errorf("expected output %q was not printed before %s, got: %q",
+				text, before, out[s.cursor:])
+			continue
+		}
+		s.cursor += i + len(text)
+	}
+}
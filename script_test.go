@@ -0,0 +1,117 @@
+package vox
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordingT - Records test failures instead of failing the test.
+type recordingT struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Cleanup(fn func()) { r.cleanups = append(r.cleanups, fn) }
+
+func wizard(v *Vox) {
+	name := v.Prompt("Project name", "app")
+	v.Println("Creating ", name)
+	if v.PromptBool("Use a database", false) {
+		v.PromptChoice("Database", []string{"postgres", "sqlite"}, 0)
+	}
+	v.PrintResult("Created "+name, nil)
+}
+
+func TestScript(t *testing.T) {
+	v := New()
+	s := v.NewScript(t)
+	s.Expect("Project name").Answer("blog")
+	s.ExpectOutput("Creating blog")
+	s.Expect("Use a database").Answer("y")
+	s.Expect("Database").Answer("sqlite")
+	s.ExpectOutput("[OK]")
+	s.Run(func() { wizard(v) })
+}
+
+func TestScriptFailures(t *testing.T) {
+	for _, test := range []struct {
+		setup    func(s *Script)
+		expected string
+	}{
+		{
+			func(s *Script) {
+				s.Expect("Project name").Answer("blog")
+				s.Expect("Database")
+			},
+			`[prompt "Use a database" was asked, expected "Database" expected prompt "Database" was not asked]`,
+		},
+		{
+			func(s *Script) {
+				s.Expect("Project name").Answer("blog")
+				s.ExpectOutput("Creating app")
+				s.Expect("Use a database")
+			},
+			`[expected output "Creating app" was not printed before prompt Use a database, got: "blog\nCreating blog\nUse a database [N]: "]`,
+		},
+		{
+			func(s *Script) {
+				s.Expect("Project name")
+				s.Expect("Use a database").Answer("no")
+				s.Expect("Email")
+			},
+			`[expected prompt "Email" was not asked]`,
+		},
+		{
+			func(s *Script) {},
+			`[unexpected prompt "Project name" unexpected prompt "Use a database"]`,
+		},
+	} {
+		v := New()
+		rt := &recordingT{}
+		s := v.NewScript(rt)
+		test.setup(s)
+		wizard(v)
+		for _, fn := range rt.cleanups {
+			fn()
+		}
+		if fmt.Sprint(rt.errors) != test.expected {
+			t.Errorf("incorrect failures: %q", rt.errors)
+		}
+	}
+}
+
+func TestScriptRestores(t *testing.T) {
+	v := New()
+	pipeline := &TestPipeline{}
+	v.SetPipelines(pipeline)
+	v.SetInput(strings.NewReader("typed\n"))
+
+	rt := &recordingT{}
+	s := v.NewScript(rt)
+	s.Expect("Project name").Answer("blog")
+	if res := v.Prompt("Project name", ""); res != "blog" {
+		t.Errorf("incorrect scripted answer: %q", res)
+	}
+	for _, fn := range rt.cleanups {
+		fn()
+	}
+
+	v.Println("after")
+	if pipeline.All() != "after\n" {
+		t.Errorf("pipelines not restored: %q", pipeline.All())
+	}
+	if res := v.Prompt("Project name", ""); res != "typed" {
+		t.Errorf("input not restored: %q", res)
+	}
+	if len(rt.errors) != 0 {
+		t.Errorf("unexpected failures: %q", rt.errors)
+	}
+}
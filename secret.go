@@ -0,0 +1,162 @@
+package vox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ErrInterrupted is returned when a prompt reading from a terminal is
+// interrupted with ctrl-c, or an interactive menu is canceled.
+var ErrInterrupted = errors.New("vox: prompt interrupted")
+
+// PromptSecret - Prompts for a value, such as a password, without echoing the
+// input. If the input stream is not a terminal the value is read as a normal
+// line. The value is never sent to any pipeline.
+func PromptSecret(name string) string { return v.PromptSecret(name) }
+
+// PromptSecret - Prompts for a value, such as a password, without echoing the
+// input. If the input stream is not a terminal the value is read as a normal
+// line. The value is never sent to any pipeline. If the prompt is interrupted
+// with ctrl-c the terminal is restored and the interrupt is sent to the
+// process again.
+func (v *Vox) PromptSecret(name string) string {
+	value, err := v.PromptSecretContext(context.Background(), name)
+	if err == ErrInterrupted {
+		raiseInterrupt()
+	}
+	return value
+}
+
+// PromptSecretContext - Prompts for a value without echoing the input like
+// PromptSecret. Errors are returned as they are by PromptContext, and
+// ErrInterrupted is returned if the prompt is interrupted with ctrl-c. When
+// reading from a terminal the context is only checked before reading starts.
+func PromptSecretContext(ctx context.Context, name string) (string, error) {
+	return v.PromptSecretContext(ctx, name)
+}
+
+// PromptSecretContext - Prompts for a value without echoing the input like
+// PromptSecret. Errors are returned as they are by PromptContext, and
+// ErrInterrupted is returned if the prompt is interrupted with ctrl-c. When
+// reading from a terminal the context is only checked before reading starts.
+func (v *Vox) PromptSecretContext(ctx context.Context, name string) (string, error) {
+	v.Printf("%s%s : %s", Yellow, name, ResetColor)
+	// answers are not printed
+	if s, ok, err := v.scriptedAnswer(name); ok {
+		v.Print("\n")
+		return s, err
+	}
+	fd, ok := terminalFd(v.input())
+	if !ok {
+		return v.readLineContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		v.Print("\n")
+		return "", err
+	}
+	v.mu.Lock()
+	mask := v.secretMask
+	v.mu.Unlock()
+
+	value, err := v.readMasked(fd, mask)
+	v.Print("\n")
+	return value, err
+}
+
+// SetSecretMask - Sets a character that is printed for each character typed
+// into PromptSecret. If zero, which is the default, nothing is printed.
+func SetSecretMask(mask rune) { v.SetSecretMask(mask) }
+
+// SetSecretMask - Sets a character that is printed for each character typed
+// into PromptSecret. If zero, which is the default, nothing is printed.
+func (v *Vox) SetSecretMask(mask rune) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.secretMask = mask
+}
+
+// readMasked - Reads a line from a terminal in raw mode, printing the mask on
+// standard out for each character. If the mask is zero, or standard out is
+// not a terminal, nothing is printed.
+// Raw mode is used even without a mask so that ctrl-c can restore the
+// terminal, and returns ErrInterrupted.
+func (v *Vox) readMasked(fd int, mask rune) (string, error) {
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		b, err := term.ReadPassword(fd)
+		return string(b), err
+	}
+	defer term.Restore(fd, state)
+
+	if !isTerminal(os.Stdout) {
+		mask = 0
+	}
+	reader := v.inputReader()
+	var value []byte
+	for {
+		c, err := reader.ReadByte()
+		if err != nil {
+			return string(value), err
+		}
+		switch c {
+		case '\r', '\n':
+			return string(value), nil
+		case 3:
+			// ctrl-c discards the value
+			return "", ErrInterrupted
+		case 4:
+			if len(value) == 0 {
+				return "", io.EOF
+			}
+		case 8, 127:
+			if len(value) > 0 {
+				_, size := utf8.DecodeLastRune(value)
+				value = value[:len(value)-size]
+				// without a mask nothing was echoed, and the prompt must not
+				// be erased
+				if mask != 0 {
+					v.writeTerminal("\b \b")
+				}
+			}
+		default:
+			if c < ' ' {
+				continue
+			}
+			value = append(value, c)
+			if mask == 0 || !utf8.RuneStart(c) {
+				continue
+			}
+			v.writeTerminal(string(mask))
+		}
+	}
+}
+
+// raiseInterrupt - Sends an interrupt to the process, for prompts that cannot
+// return ErrInterrupted. Unless the program handles the signal it exits as it
+// would for ctrl-c outside of a prompt.
+func raiseInterrupt() {
+	if p, err := os.FindProcess(os.Getpid()); err == nil {
+		p.Signal(os.Interrupt)
+	}
+}
+
+// isTerminal - Returns true if the input stream is attached to a terminal.
+func isTerminal(in io.Reader) bool {
+	_, ok := terminalFd(in)
+	return ok
+}
+
+// terminalFd - Returns the file descriptor of the input stream if it is
+// attached to a terminal.
+func terminalFd(in io.Reader) (int, bool) {
+	f, ok := in.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0, false
+	}
+	return int(f.Fd()), true
+}
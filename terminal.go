@@ -0,0 +1,130 @@
+package vox
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ColorProfile - The set of colors supported by a terminal.
+type ColorProfile int
+
+const (
+	// AutoColors - Detect the supported colors from the terminal and
+	// environment.
+	AutoColors ColorProfile = iota
+	// NoColors - Colors and styles are not supported.
+	NoColors
+	// BasicColors - The 8 basic colors and their bright variants.
+	BasicColors
+	// ExtendedColors - The 256 color palette.
+	ExtendedColors
+	// TrueColors - 24 bit colors.
+	TrueColors
+)
+
+// DetectColorProfile - Determines the colors supported by the terminal attached
+// to a file, usually os.Stdout. Colors are disabled when the file is not a
+// terminal, when NO_COLOR is set or when TERM is dumb. FORCE_COLOR enables
+// colors regardless of the terminal, and can be set to 0-3 to choose the
+// profile.
+func DetectColorProfile(f *os.File) ColorProfile {
+	return detectColorProfile(isatty.IsTerminal(f.Fd()), os.LookupEnv)
+}
+
+func detectColorProfile(tty bool, lookupEnv func(string) (string, bool)) ColorProfile {
+	if force, ok := lookupEnv("FORCE_COLOR"); ok {
+		forced := BasicColors
+		switch strings.ToLower(force) {
+		case "0", "false":
+			return NoColors
+		case "2":
+			forced = ExtendedColors
+		case "3":
+			forced = TrueColors
+		}
+		if p := termColorProfile(lookupEnv); p > forced {
+			return p
+		}
+		return forced
+	}
+	if noColor, ok := lookupEnv("NO_COLOR"); ok && noColor != "" {
+		return NoColors
+	}
+	if term, _ := lookupEnv("TERM"); term == "dumb" || !tty {
+		return NoColors
+	}
+	return termColorProfile(lookupEnv)
+}
+
+// termColorProfile - Determines the colors supported by the terminal type set
+// in the environment.
+func termColorProfile(lookupEnv func(string) (string, bool)) ColorProfile {
+	colorTerm, _ := lookupEnv("COLORTERM")
+	term, _ := lookupEnv("TERM")
+	switch {
+	case colorTerm == "truecolor" || colorTerm == "24bit",
+		strings.Contains(term, "truecolor"), strings.Contains(term, "direct"):
+		return TrueColors
+	case strings.Contains(term, "256color"):
+		return ExtendedColors
+	}
+	return BasicColors
+}
+
+// downgradeParams - Converts the parameters of an SGR sequence to use only the
+// colors supported by the profile. All parameters are removed for NoColors.
+func downgradeParams(params []string, p ColorProfile) []string {
+	if p == NoColors {
+		return nil
+	}
+	out := make([]string, 0, len(params))
+	for i := 0; i < len(params); i++ {
+		if (params[i] != "38" && params[i] != "48") || i+1 == len(params) {
+			out = append(out, params[i])
+			continue
+		}
+		base := 30
+		if params[i] == "48" {
+			base = 40
+		}
+		var cv colorValue
+		switch {
+		case params[i+1] == "5" && i+2 < len(params):
+			cv = colorValue{mode: color256, n: parseUint8(params[i+2])}
+			i += 2
+		case params[i+1] == "2" && i+4 < len(params):
+			cv = colorValue{
+				mode: trueColor,
+				r:    parseUint8(params[i+2]),
+				g:    parseUint8(params[i+3]),
+				b:    parseUint8(params[i+4]),
+			}
+			i += 4
+		default:
+			out = append(out, params[i])
+			continue
+		}
+		out = append(out, cv.downgrade(p).params(base))
+	}
+	return out
+}
+
+// writeTerminal - Writes directly to standard out, for interactive prompts
+// that must be seen on the terminal even when no rich pipeline is attached.
+// Colors are converted to those the terminal supports.
+func (v *Vox) writeTerminal(s string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	show := v.bars.hide()
+	defer show()
+	switch p := DetectColorProfile(os.Stdout); p {
+	case NoColors, BasicColors, ExtendedColors:
+		s = rewriteSGR(s, func(params []string) []string {
+			return downgradeParams(params, p)
+		})
+	}
+	io.WriteString(os.Stdout, s)
+}
@@ -0,0 +1,83 @@
+package vox
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDetectColorProfile(t *testing.T) {
+	for _, tc := range []struct {
+		tty      bool
+		env      map[string]string
+		expected ColorProfile
+	}{
+		{true, map[string]string{"TERM": "xterm"}, BasicColors},
+		{true, map[string]string{"TERM": "xterm-256color"}, ExtendedColors},
+		{true, map[string]string{"TERM": "xterm", "COLORTERM": "truecolor"}, TrueColors},
+		{false, map[string]string{"TERM": "xterm-256color"}, NoColors},
+		{true, map[string]string{"TERM": "dumb"}, NoColors},
+		{true, map[string]string{"TERM": "xterm", "NO_COLOR": "1"}, NoColors},
+		{true, map[string]string{"TERM": "xterm", "NO_COLOR": ""}, BasicColors},
+		{false, map[string]string{"FORCE_COLOR": ""}, BasicColors},
+		{false, map[string]string{"FORCE_COLOR": "3"}, TrueColors},
+		{false, map[string]string{"FORCE_COLOR": "1", "TERM": "xterm-256color"}, ExtendedColors},
+		{true, map[string]string{"FORCE_COLOR": "0", "TERM": "xterm"}, NoColors},
+		{false, map[string]string{"FORCE_COLOR": "1", "NO_COLOR": "1"}, BasicColors},
+	} {
+		lookupEnv := func(key string) (string, bool) {
+			val, ok := tc.env[key]
+			return val, ok
+		}
+		if p := detectColorProfile(tc.tty, lookupEnv); p != tc.expected {
+			t.Errorf("incorrect profile for %v %v: %d", tc.tty, tc.env, p)
+		}
+	}
+}
+
+func TestColorDowngrade(t *testing.T) {
+	for _, tc := range []struct {
+		color    Color
+		profile  ColorProfile
+		expected Color
+	}{
+		{RGB(255, 0, 0), ExtendedColors, Color256(196)},
+		{RGB(128, 128, 128), ExtendedColors, Color256(244)},
+		{RGB(250, 10, 10), BasicColors, BrightRed},
+		{RGB(0, 0, 200), BasicColors, Blue},
+		{Color256(3), BasicColors, Yellow},
+		{Color256(12), BasicColors, BrightBlue},
+		{Color256(46), BasicColors, BrightGreen},
+		{White.On(RGB(0, 0, 0)), BasicColors, White.On(Black)},
+		{Style(Bold, RGB(1, 2, 3)), TrueColors, Style(Bold, RGB(1, 2, 3))},
+		{Style(Bold, Red), NoColors, Color{}},
+	} {
+		if c := tc.color.Downgrade(tc.profile); c != tc.expected {
+			t.Errorf("incorrect downgrade of %q: %q %q", tc.color.String(),
+				c.String(), tc.expected.String())
+		}
+	}
+}
+
+func TestRewriteSGR(t *testing.T) {
+	in := fmt.Sprint(Style(Bold, RGB(255, 0, 0)), "red", ResetColor,
+		"\u001b[2K", Color256(46).On(Color256(21)), "green")
+	for profile, expected := range map[ColorProfile]string{
+		TrueColors: in,
+		ExtendedColors: fmt.Sprint(Style(Bold, Color256(196)), "red", ResetColor,
+			"\u001b[2K", Color256(46).On(Color256(21)), "green"),
+		BasicColors: fmt.Sprint(Style(Bold, BrightRed), "red", ResetColor,
+			"\u001b[2K", BrightGreen.On(Blue), "green"),
+		NoColors: "red\u001b[2Kgreen",
+	} {
+		out := rewriteSGR(in, func(params []string) []string {
+			if profile == TrueColors {
+				return params
+			}
+			return downgradeParams(params, profile)
+		})
+		if out != expected {
+			t.Errorf("incorrect output for profile %d: %q %q", profile, out,
+				expected)
+		}
+	}
+}
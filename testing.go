@@ -1,9 +1,11 @@
 package vox
 
 import (
-	"io"
-	"io/ioutil"
-	"os"
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
 )
 
 // Test - Sets up vox to print and read from in memory locations for testing.
@@ -13,6 +15,7 @@ func Test() *TestPipeline { return v.Test() }
 func (v *Vox) Test() *TestPipeline {
 	pl := &TestPipeline{}
 	v.SetPipelines(pl)
+	v.ClearInput()
 	return pl
 }
 
@@ -20,17 +23,143 @@ func (v *Vox) Test() *TestPipeline {
 func SendInput(str string) error { return v.SendInput(str) }
 
 // ClearInput - Clears the input buffer. Useful during testing.
-func ClearInput() {
-	in, _ := ioutil.TempFile("", "")
-	v.SetInput(in)
+func ClearInput() { v.ClearInput() }
+
+// ClearInput - Clears the input buffer. Useful during testing.
+func (v *Vox) ClearInput() {
+	v.SetInput(&inputQueue{})
 }
 
-// SendInput - Writes data into the input stream. Used for testing.
+// SendInput - Writes data into the input stream. Used for testing. Input is
+// queued, so the answers to several prompts can be sent up front. If the
+// input stream was not set up for testing it is replaced.
 func (v *Vox) SendInput(str string) error {
-	_, err := io.WriteString(v.in, str)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	q, ok := v.in.(*inputQueue)
+	if !ok {
+		q = &inputQueue{}
+		v.setInput(q)
+	}
+	_, err := q.Write([]byte(str))
+	return err
+}
+
+// inputQueue - An input stream for testing. Reads return the queued input, or
+// io.EOF when all of it has been read.
+type inputQueue struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (q *inputQueue) Read(p []byte) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.buf.Read(p)
+}
+
+func (q *inputQueue) Write(p []byte) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.buf.Write(p)
+}
+
+// Lines - Returns the output split into lines, without line endings.
+func (t *TestPipeline) Lines() []string {
+	out := strings.TrimSuffix(t.All(), "\n")
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+// AssertContains - Fails the test if the output does not contain s.
+func (t *TestPipeline) AssertContains(tb testing.TB, s string) {
+	tb.Helper()
+	assertContains(tb, t.All(), s)
+}
+
+// AssertContainsPlain - Fails the test if the output, with escape sequences
+// removed, does not contain s.
+func (t *TestPipeline) AssertContainsPlain(tb testing.TB, s string) {
+	tb.Helper()
+	assertContains(tb, StripANSI(t.All()), s)
+}
+
+// AssertLine - Fails the test if line n of the output is not s. Lines are
+// counted from zero, and negative numbers count back from the last line.
+func (t *TestPipeline) AssertLine(tb testing.TB, n int, s string) {
+	tb.Helper()
+	assertLine(tb, t.Lines(), n, s)
+}
+
+// AssertLinePlain - Fails the test if line n of the output, with escape
+// sequences removed, is not s.
+func (t *TestPipeline) AssertLinePlain(tb testing.TB, n int, s string) {
+	tb.Helper()
+	lines := t.Lines()
+	for i := range lines {
+		lines[i] = StripANSI(lines[i])
+	}
+	assertLine(tb, lines, n, s)
+}
+
+// AssertMatches - Fails the test if the output does not match the regular
+// expression.
+func (t *TestPipeline) AssertMatches(tb testing.TB, pattern string) {
+	tb.Helper()
+	assertMatches(tb, t.All(), pattern)
+}
+
+// AssertMatchesPlain - Fails the test if the output, with escape sequences
+// removed, does not match the regular expression.
+func (t *TestPipeline) AssertMatchesPlain(tb testing.TB, pattern string) {
+	tb.Helper()
+	assertMatches(tb, StripANSI(t.All()), pattern)
+}
+
+// AssertNoErrors - Fails the test if an error or fatal message was logged.
+func (t *TestPipeline) AssertNoErrors(tb testing.TB) {
+	tb.Helper()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, e := range t.Entries {
+		if e.Level >= ErrorLevel {
+			tb.Errorf("%s message logged: %s", e.Level, e.Message)
+		}
+	}
+}
+
+func assertContains(tb testing.TB, out, s string) {
+	tb.Helper()
+	if !strings.Contains(out, s) {
+		tb.Errorf("output does not contain %q:\n%s", s, out)
+	}
+}
+
+func assertLine(tb testing.TB, lines []string, n int, s string) {
+	tb.Helper()
+	i := n
+	if i < 0 {
+		i += len(lines)
+	}
+	if i < 0 || i >= len(lines) {
+		tb.Errorf("output has no line %d, it has %d lines", n, len(lines))
+		return
+	}
+	if lines[i] != s {
+		tb.Errorf("line %d of output is %q, expected %q", n, lines[i], s)
+	}
+}
+
+func assertMatches(tb testing.TB, out, pattern string) {
+	tb.Helper()
+	re, err := regexp.Compile(pattern)
 	if err != nil {
-		return err
+		tb.Errorf("invalid pattern: %s", err)
+		return
+	}
+	if !re.MatchString(out) {
+		tb.Errorf("output does not match %s:\n%s", pattern, out)
 	}
-	v.in.Seek(0, os.SEEK_SET)
-	return nil
 }
@@ -0,0 +1,46 @@
+package vox
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTestPipelineAssertions(t *testing.T) {
+	v := New()
+	pipeline := v.Test()
+	v.Println(Green, "ready", ResetColor)
+	v.PrintResult("Build", nil)
+	v.Info("done")
+
+	pipeline.AssertContainsPlain(t, "ready")
+	pipeline.AssertContains(t, Sprintc(Green, "ready"))
+	pipeline.AssertLinePlain(t, 1, "Build"+strings.Repeat(" ", 55)+"[OK]")
+	pipeline.AssertLinePlain(t, -1, "done")
+	pipeline.AssertMatchesPlain(t, `Build +\[OK\]`)
+	pipeline.AssertNoErrors(t)
+
+	rt := &recordingT{}
+	v.Error("failed")
+	pipeline.AssertContains(rt, "ready\n")
+	pipeline.AssertLine(rt, 5, "done")
+	pipeline.AssertLinePlain(rt, 0, "steady")
+	pipeline.AssertMatches(rt, `^done`)
+	pipeline.AssertMatches(rt, `(`)
+	pipeline.AssertNoErrors(rt)
+	expected := []string{
+		"output does not contain \"ready\\n\"",
+		"output has no line 5, it has 4 lines",
+		"line 0 of output is \"ready\", expected \"steady\"",
+		"output does not match ^done",
+		"invalid pattern",
+		"error message logged: failed",
+	}
+	if len(rt.errors) != len(expected) {
+		t.Fatalf("incorrect failures: %q", rt.errors)
+	}
+	for i, e := range expected {
+		if !strings.HasPrefix(rt.errors[i], e) {
+			t.Errorf("incorrect failure: %q", rt.errors[i])
+		}
+	}
+}
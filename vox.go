@@ -1,20 +1,34 @@
 package vox
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
 // Vox - The main class for Vox all functions are called from this object.
-// Direct functions use an auto generated Vox object.
+// Direct functions use an auto generated Vox object. A Vox object is safe for
+// use from multiple goroutines.
 type Vox struct {
-	mu        sync.Mutex
-	buf       []byte
-	in        *os.File
-	progress  *progress
-	pipelines []Pipeline
+	mu          sync.Mutex
+	in          io.Reader
+	reader      *bufio.Reader
+	progress    *ProgressBar
+	bars        *progressManager
+	pipelines   []EventPipeline
+	level       Level
+	secretMask  rune
+	maxAttempts int
+	// pending - A read of the input stream that was still waiting when a
+	// prompt's context was done.
+	pending        chan lineResult
+	answers        []AnswerSource
+	nonInteractive bool
+	script         *Script
 }
 
 var v *Vox
@@ -26,18 +40,17 @@ func init() {
 // New - creates a new Vox instance. This can be used as an alternative to the
 // singletone instance. If multiple Vox instances are needed.
 func New() *Vox {
-	v := &Vox{}
+	v := &Vox{bars: newProgressManager(os.Stdout)}
+	v.SetInput(os.Stdin)
 	v.SetPipelines(&ConsolePipeline{})
 	return v
 }
 
 // Write writes data into the log
 func (v *Vox) Write(p []byte) (n int, err error) {
-	for _, pl := range v.pipelines {
-		_, err := pl.Write(p)
-		if err != nil {
-			return 0, err
-		}
+	err = v.emit(&Entry{Segments: []Segment{{Text: string(p)}}})
+	if err != nil {
+		return 0, err
 	}
 	return len(p), nil
 }
@@ -51,11 +64,7 @@ func Sprintc(c Color, args ...interface{}) string {
 
 // SetPipelines replaces all pipelines with the passed pipeline
 func (v *Vox) SetPipelines(p Pipeline) {
-	if err := p.Initialize(); err == nil {
-		v.pipelines = []Pipeline{p}
-	} else {
-		v.pipelines = []Pipeline{}
-	}
+	v.SetEventPipelines(AdaptPipeline(p))
 }
 
 // SetPipelines replaces all pipelines with the passed pipeline
@@ -63,52 +72,138 @@ func SetPipelines(p Pipeline) { v.SetPipelines(p) }
 
 // AddPipeline adds a new pipeline to the logger
 func (v *Vox) AddPipeline(p Pipeline) {
+	v.AddEventPipeline(AdaptPipeline(p))
+}
+
+// AddPipeline adds a new pipeline to the logger
+func AddPipeline(p Pipeline) { v.AddPipeline(p) }
+
+// SetEventPipelines replaces all pipelines with the passed event pipeline
+func (v *Vox) SetEventPipelines(p EventPipeline) {
+	pipelines := []EventPipeline{}
 	if err := p.Initialize(); err == nil {
+		pipelines = append(pipelines, p)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pipelines = pipelines
+}
+
+// SetEventPipelines replaces all pipelines with the passed event pipeline
+func SetEventPipelines(p EventPipeline) { v.SetEventPipelines(p) }
+
+// AddEventPipeline adds a new event pipeline to the logger
+func (v *Vox) AddEventPipeline(p EventPipeline) {
+	if err := p.Initialize(); err == nil {
+		v.mu.Lock()
+		defer v.mu.Unlock()
 		v.pipelines = append(v.pipelines, p)
 	}
 }
 
-// AddPipeline adds a new pipeline to the logger
-func AddPipeline(p Pipeline) { v.AddPipeline(p) }
+// AddEventPipeline adds a new event pipeline to the logger
+func AddEventPipeline(p EventPipeline) { v.AddEventPipeline(p) }
 
 // SetInput - Sets the input stream for VOX. This is mainly used for testing.
-func SetInput(in *os.File) { v.SetInput(in) }
+func SetInput(in io.Reader) { v.SetInput(in) }
 
 // SetInput - Sets the input stream for VOX. This is mainly used for testing.
-func (v *Vox) SetInput(in *os.File) {
+func (v *Vox) SetInput(in io.Reader) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
+	v.setInput(in)
+}
+
+// setInput - Sets the input stream. The lock must be held.
+func (v *Vox) setInput(in io.Reader) {
 	v.in = in
+	v.reader = bufio.NewReader(in)
+	v.pending = nil
 }
 
-func (v *Vox) output(s string) error {
-	for _, pl := range v.pipelines {
-		if !pl.Config().Plain {
-			v.buf = v.buf[:0]
-			v.buf = append(v.buf, s...)
+// input - Returns the current input stream.
+func (v *Vox) input() io.Reader {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.in
+}
+
+// inputReader - Returns the buffered reader for the input stream, which is
+// shared by all prompts so that buffered input is not lost between them.
+func (v *Vox) inputReader() *bufio.Reader {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.reader
+}
 
-			_, err := pl.Write(v.buf)
-			if err != nil {
-				println(err.Error())
+// emit - Sends an entry to every pipeline that accepts it. The plain message
+// is built from the segments with all escape sequences removed. Errors from
+// the pipelines are printed and the first one is returned. Entries are sent one
+// at a time so that output from different goroutines is not interleaved.
+func (v *Vox) emit(e *Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Message == "" {
+		e.Message = StripANSI(e.text())
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// output is printed above the progress bars
+	show := v.bars.hide()
+	defer show()
+	var firstErr error
+	for _, pl := range v.pipelines {
+		cfg := pl.Config()
+		if e.Level != NoLevel && e.Level < cfg.MinLevel {
+			continue
+		}
+		if cfg.Plain && e.target == richOutputs ||
+			!cfg.Plain && e.target == plainOutputs {
+			continue
+		}
+		if err := pl.WriteEntry(e); err != nil {
+			println(err.Error())
+			if firstErr == nil {
+				firstErr = err
 			}
 		}
 	}
-	return nil
+	return firstErr
 }
 
-func (v *Vox) outputPlain(s string) error {
-	for _, pl := range v.pipelines {
-		if pl.Config().Plain {
-			v.buf = v.buf[:0]
-			v.buf = append(v.buf, s...)
+// Flush - Waits until pipelines that write in the background, such as the
+// AsyncPipeline, have written all of their output.
+func Flush() { v.Flush() }
 
-			_, err := pl.Write(v.buf)
-			if err != nil {
-				println(err.Error())
-			}
+// Flush - Waits until pipelines that write in the background, such as the
+// AsyncPipeline, have written all of their output.
+func (v *Vox) Flush() {
+	v.mu.Lock()
+	pipelines := make([]EventPipeline, len(v.pipelines))
+	copy(pipelines, v.pipelines)
+	v.mu.Unlock()
+	for _, pl := range pipelines {
+		if f, ok := pl.(flusher); ok {
+			f.Flush()
 		}
 	}
-	return nil
+}
+
+// output - Sends text only to pipelines that are not plain.
+func (v *Vox) output(s string) error {
+	return v.emit(&Entry{
+		Segments: []Segment{{Text: s}},
+		target:   richOutputs,
+	})
+}
+
+// outputPlain - Sends text only to plain pipelines.
+func (v *Vox) outputPlain(s string) error {
+	return v.emit(&Entry{
+		Segments: []Segment{{Text: s}},
+		target:   plainOutputs,
+	})
 }
 
 // Printf - Prints a formatted string using a template and as series of
@@ -126,8 +221,7 @@ func Print(s ...interface{}) { v.Print(s...) }
 
 // Print - Prints a number of variables.
 func (v *Vox) Print(s ...interface{}) {
-	v.output(fmt.Sprint(s...))
-	v.outputPlain(fmt.Sprint(s...))
+	v.emit(&Entry{Segments: segments(s...)})
 }
 
 // Printc - sends the string results of the objects prefixed with a color code.
@@ -138,13 +232,12 @@ func Printc(c Color, s ...interface{}) { v.Printc(c, s...) }
 // For plain pipelines the color code will be stripped out.
 func (v *Vox) Printc(c Color, s ...interface{}) {
 	str := fmt.Sprint(s...)
-	v.output(fmt.Sprint(c, str, ResetColor))
-	v.outputPlain(fmt.Sprint(s...))
+	v.emit(&Entry{Segments: segments(c, str, ResetColor)})
 }
 
 // PrintRich will only print to non plain pipelines. It is safe to embed color
 // codes in this function.
-func PrintRich(c Color, s ...interface{}) { v.PrintRich(s...) }
+func PrintRich(s ...interface{}) { v.PrintRich(s...) }
 
 // PrintRich will only print to non plain pipelines. It is safe to embed color
 // codes in this function.
@@ -153,12 +246,12 @@ func (v *Vox) PrintRich(s ...interface{}) {
 }
 
 // PrintPlain will only print to plain pipelines, such as the FilePipeline
-func PrintPlain(c Color, s ...interface{}) { v.PrintPlain(s...) }
+func PrintPlain(s ...interface{}) { v.PrintPlain(s...) }
 
-// PrintPlain will only print to non plain pipelines. It is safe to embed color
-// codes in this function.
+// PrintPlain will only print to plain pipelines, such as the FilePipeline. Any
+// color codes are removed.
 func (v *Vox) PrintPlain(s ...interface{}) {
-	v.output(fmt.Sprint(s...))
+	v.outputPlain(fmt.Sprint(s...))
 }
 
 // Println - Prints a number of tokens ending with a new line.
@@ -166,8 +259,7 @@ func Println(s ...interface{}) { v.Println(s...) }
 
 // Println - Prints a number of tokens ending with a new line.
 func (v *Vox) Println(s ...interface{}) {
-	str := fmt.Sprint(s...) + "\n"
-	v.Print(str)
+	v.emit(&Entry{Segments: append(segments(s...), Segment{Text: "\n"})})
 }
 
 // Printlnc - Prints a number of tokens followed by a new line. This output is
@@ -178,8 +270,7 @@ func Printlnc(c Color, s ...interface{}) { v.Printlnc(c, s...) }
 // also wrapped in a color code and a reset.
 func (v *Vox) Printlnc(c Color, s ...interface{}) {
 	outStr := fmt.Sprint(s...)
-	v.output(fmt.Sprint(c, outStr, ResetColor, "\n"))
-	v.outputPlain(outStr + "\n")
+	v.emit(&Entry{Segments: segments(c, outStr, ResetColor, "\n")})
 }
 
 // PrintProperty - Prints a property name and value. The value will be right
@@ -209,10 +300,6 @@ func PrintResult(desc string, err error) { v.PrintResult(desc, err) }
 // it will result in a success. The status code will also be right aligned and
 // color coded based on the result.
 func (v *Vox) PrintResult(desc string, err error) {
-	var (
-		out      string
-		outPlain string
-	)
 	resultColor := Red
 	resultText := "FAIL"
 	if err == nil {
@@ -220,23 +307,19 @@ func (v *Vox) PrintResult(desc string, err error) {
 		resultText = "OK"
 	}
 	desc += strings.Repeat(" ", 60-len(desc))
-	out += fmt.Sprint(
+	out := segments(
 		White, desc,
 		Yellow, "[", resultColor, resultText, Yellow, "]",
 		ResetColor,
 		"\n",
 	)
+	// plain output keeps a space between the description and the result
+	plain := fmt.Sprintf("%s [%s]\n", desc, resultText)
 	if err != nil {
-		out += fmt.Sprint(Red, err.Error(), "\n")
-	}
-	v.output(out)
-
-	outPlain += fmt.Sprintf("%s [%s]\n", desc, resultText)
-	if err != nil {
-		outPlain += err.Error() + "\n"
+		out = append(out, segments(Red, err.Error(), "\n")...)
+		plain += err.Error() + "\n"
 	}
-
-	v.outputPlain(outPlain)
+	v.emit(&Entry{Segments: out, Message: plain})
 }
 
 // Errorf - Print error output. Console output is colored red.
@@ -252,7 +335,7 @@ func Error(args ...interface{}) { v.Error(args...) }
 
 // Error - Print output as an error. Console output is colored red.
 func (v *Vox) Error(args ...interface{}) {
-	v.Printlnc(Red, fmt.Sprint(args...))
+	v.log(ErrorLevel, nil, args...)
 }
 
 // Infof - Print an info output. Console output is colored white.
@@ -268,7 +351,7 @@ func Info(args ...interface{}) { v.Info(args...) }
 
 // Info - Print an info output. Console output is colored white.
 func (v *Vox) Info(args ...interface{}) {
-	v.Printlnc(White, fmt.Sprint(args...))
+	v.log(InfoLevel, nil, args...)
 }
 
 // Alertf - Print an info output. Console output is colored yellow.
@@ -284,7 +367,7 @@ func Alert(args ...interface{}) { v.Alert(args...) }
 
 // Alert - Print an info output. Console output is colored yellow.
 func (v *Vox) Alert(args ...interface{}) {
-	v.Printlnc(Yellow, fmt.Sprint(args...))
+	v.log(AlertLevel, nil, args...)
 }
 
 // Debugf - Print an debug output. Debug output is not colored.
@@ -300,7 +383,23 @@ func Debug(args ...interface{}) { v.Debug(args...) }
 
 // Debug - Print an debug output. Debug output is not colored.
 func (v *Vox) Debug(args ...interface{}) {
-	v.Println(fmt.Sprint(args...))
+	v.log(DebugLevel, nil, args...)
+}
+
+// Tracef - Print trace output. Trace output is not colored.
+func Tracef(format string, args ...interface{}) { v.Tracef(format, args...) }
+
+// Tracef - Print trace output. Trace output is not colored.
+func (v *Vox) Tracef(format string, args ...interface{}) {
+	v.Trace(fmt.Sprintf(format, args...))
+}
+
+// Trace - Print trace output. Trace output is not colored.
+func Trace(args ...interface{}) { v.Trace(args...) }
+
+// Trace - Print trace output. Trace output is not colored.
+func (v *Vox) Trace(args ...interface{}) {
+	v.log(TraceLevel, nil, args...)
 }
 
 // Fatal - Prints an error message and then exits the application.
@@ -308,7 +407,8 @@ func Fatal(args ...interface{}) { v.Fatal(args...) }
 
 // Fatal - Prints an error message and then exits the application.
 func (v *Vox) Fatal(args ...interface{}) {
-	v.Error(args...)
+	v.log(FatalLevel, nil, args...)
+	v.Flush()
 	os.Exit(-1)
 }
 
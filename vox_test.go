@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -78,4 +79,58 @@ func TestPrintResult(t *testing.T) {
 			t.Errorf("incorrect string: \n%s%s", pipeline.Last(), expected)
 		}
 	})
+	t.Run("plain", func(t *testing.T) {
+		v := New()
+		plain := &TestPipeline{Plain: true}
+		v.SetPipelines(plain)
+		v.PrintResult("test", nil)
+		v.PrintResult("test", errors.New("test error"))
+		expected := "test" + strings.Repeat(" ", 56) + " [OK]\n" +
+			"test" + strings.Repeat(" ", 56) + " [FAIL]\ntest error\n"
+		if plain.All() != expected {
+			t.Errorf("incorrect plain string: %q", plain.All())
+		}
+	})
+}
+
+func TestConcurrentOutput(t *testing.T) {
+	v := New()
+	pipeline := v.Test()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				v.With("worker", i).Infof("message %d", j)
+				v.Println("line ", i, " ", j)
+				v.PrintResult("task", nil)
+			}
+		}(i)
+	}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			v.AddPipeline(&TestPipeline{Plain: true})
+			v.SetLevel(TraceLevel)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			pipeline.All()
+			v.Flush()
+		}
+	}()
+	wg.Wait()
+
+	if len(pipeline.LogLines) != 10*50*3 {
+		t.Errorf("incorrect line count: %d", len(pipeline.LogLines))
+	}
+	for _, line := range pipeline.LogLines {
+		if strings.Count(line, "\n") != 1 {
+			t.Errorf("corrupted line: %q", line)
+		}
+	}
 }